@@ -0,0 +1,157 @@
+package jsonschemaline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Coerce 按 fullname 描述的结构,将字符串形式的值转换为format(未注册时按type)声明的实际类型,规则如下:
+//   - 值不存在或为null时,使用default(同样按format转换);父节点不存在时不处理
+//   - 值为空字符串时,allowEmptyValue 则保留;否则有default使用default,没有default且需要转换时记录错误
+//   - type=array 且有format时,转换数组中的每个字符串元素
+//
+// 无法转换的值保持原样,全部记录在返回的 Violations 中(错误码 VALIDATE_ERROR_CODE_FORMAT),此时output仍为转换了其它值后的结果
+func (l *Jsonschemaline) Coerce(input []byte) (output []byte, err error) {
+	if !gjson.ValidBytes(input) {
+		err = errors.Errorf("invalid json: %s", string(input))
+		return nil, err
+	}
+	output = append([]byte{}, input...)
+	violations := make(Violations, 0)
+	for _, item := range l.Items {
+		segments := strings.Split(strings.Trim(item.Fullname, "."), ".")
+		for _, path := range item.coercePaths(gjson.ParseBytes(output), "", segments) {
+			var pathViolations Violations
+			output, pathViolations, err = item.coerceValue(output, path)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, pathViolations...)
+		}
+	}
+	if len(violations) > 0 {
+		return output, violations
+	}
+	return output, nil
+}
+
+// coercePaths 沿fullname逐段查找数据,返回需要转换的全部路径(包含父节点存在、自身不存在的路径)
+func (jItem JsonschemalineItem) coercePaths(node gjson.Result, path string, segments []string) (paths []string) {
+	paths = make([]string, 0)
+	segment, rest := segments[0], segments[1:]
+	isArray := strings.HasSuffix(segment, "[]")
+	name := strings.TrimSuffix(segment, "[]")
+	value, valuePath := node, path
+	if name != "" {
+		value, valuePath = node.Get(escapeDocumentKey(name)), joinValidatePath(path, name)
+	}
+	if !value.Exists() {
+		if len(rest) == 0 && !isArray && valuePath != "" {
+			paths = append(paths, valuePath)
+		}
+		return paths
+	}
+	if !isArray {
+		if len(rest) == 0 {
+			return append(paths, valuePath)
+		}
+		return jItem.coercePaths(value, valuePath, rest)
+	}
+	if !value.IsArray() {
+		return paths
+	}
+	for i, element := range value.Array() {
+		elementPath := joinValidatePath(valuePath, strconv.Itoa(i))
+		if len(rest) == 0 {
+			paths = append(paths, elementPath)
+			continue
+		}
+		paths = append(paths, jItem.coercePaths(element, elementPath, rest)...)
+	}
+	return paths
+}
+
+// coerceValue 转换单个路径的值
+func (jItem JsonschemalineItem) coerceValue(data []byte, path string) (newData []byte, violations Violations, err error) {
+	violations = make(Violations, 0)
+	value := gjson.GetBytes(data, path)
+	coerce := jItem.coerceFunc()
+	useDefault := false
+	switch {
+	case !value.Exists() || value.Type == gjson.Null:
+		useDefault = jItem.Default != ""
+	case value.Type == gjson.String && value.Str == "":
+		if jItem.AllowEmptyValue {
+			return data, violations, nil
+		}
+		useDefault = jItem.Default != ""
+		if !useDefault && coerce != nil {
+			violations = append(violations, jItem.newViolation(path, VALIDATE_ERROR_CODE_FORMAT, fmt.Sprintf("empty value can not coerce to %s", jItem.coerceTarget())))
+			return data, violations, nil
+		}
+	}
+	if useDefault {
+		return jItem.setCoerced(data, path, jItem.Default, coerce)
+	}
+
+	switch {
+	case coerce == nil:
+		return data, violations, nil
+	case value.Type == gjson.String:
+		return jItem.setCoerced(data, path, value.Str, coerce)
+	case value.IsArray() && strings.ToLower(jItem.Type) == "array":
+		for i, element := range value.Array() {
+			if element.Type != gjson.String {
+				continue
+			}
+			var elementViolations Violations
+			data, elementViolations, err = jItem.setCoerced(data, joinValidatePath(path, strconv.Itoa(i)), element.Str, coerce)
+			if err != nil {
+				return nil, nil, err
+			}
+			violations = append(violations, elementViolations...)
+		}
+	}
+	return data, violations, nil
+}
+
+// setCoerced 转换字符串并写入,无法转换时保持原数据并记录
+func (jItem JsonschemalineItem) setCoerced(data []byte, path string, str string, coerce func(value string) (interface{}, error)) (newData []byte, violations Violations, err error) {
+	violations = make(Violations, 0)
+	var coerced interface{} = str
+	if coerce != nil {
+		coerced, err = coerce(str)
+		if err != nil {
+			violations = append(violations, jItem.newViolation(path, VALIDATE_ERROR_CODE_FORMAT, fmt.Sprintf("can not coerce %q to %s", str, jItem.coerceTarget())))
+			return data, violations, nil
+		}
+	}
+	newData, err = sjson.SetBytes(data, path, coerced)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newData, violations, nil
+}
+
+// coerceFunc 优先使用format的转换函数,format 未注册时使用type对应的转换函数
+func (jItem JsonschemalineItem) coerceFunc() (coerce func(value string) (interface{}, error)) {
+	if f, ok := GetFormat(jItem.Format); ok {
+		return f.Coerce
+	}
+	if f, ok := GetFormat(strings.ToLower(jItem.Type)); ok {
+		return f.Coerce
+	}
+	return nil
+}
+
+func (jItem JsonschemalineItem) coerceTarget() (target string) {
+	if jItem.Format != "" {
+		return jItem.Format
+	}
+	return jItem.Type
+}
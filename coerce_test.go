@@ -0,0 +1,46 @@
+package jsonschemaline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestCoerce(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=pageSize,dst=pageSize,format=int,default=20
+fullname=price,dst=price,format=float
+fullname=online,dst=online,format=bool
+fullname=keyword,dst=keyword,format=int,allowEmptyValue
+fullname=name,dst=name,default=guest
+fullname=ids,dst=ids,type=array,format=int
+fullname=items[].id,dst=items.#.id,format=int,default=0
+fullname=extra.level,dst=extra.level,format=int,default=1`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+
+	t.Run("coerce", func(t *testing.T) {
+		input := `{"price":"9.99","online":"true","keyword":"","name":"","ids":["1","2",3],"items":[{"id":"7"},{}]}`
+		output, err := lineschema.Coerce([]byte(input))
+		require.NoError(t, err)
+		expected := `{"price":9.99,"online":true,"keyword":"","name":"guest","ids":[1,2,3],"items":[{"id":7},{"id":0}],"pageSize":20}`
+		assert.Equal(t, expected, string(output))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		input := `{"pageSize":"","price":"abc","items":[{"id":"x"}]}`
+		output, err := lineschema.Coerce([]byte(input))
+		require.Error(t, err)
+		violations, ok := err.(jsonschemaline.Violations)
+		require.True(t, ok)
+		paths := make([]string, 0)
+		for _, v := range violations {
+			assert.Equal(t, jsonschemaline.VALIDATE_ERROR_CODE_FORMAT, v.Code)
+			paths = append(paths, v.Path)
+		}
+		assert.Equal(t, []string{"price", "items.0.id"}, paths)
+		assert.Equal(t, `{"pageSize":20,"price":"abc","items":[{"id":"x"}],"name":"guest"}`, string(output))
+	})
+}
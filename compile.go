@@ -0,0 +1,147 @@
+package jsonschemaline
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CompiledSchema 编译后的lineschema,预先计算fullname索引、gjson路径、json schema、Transformer、校验用的pattern,
+// 生成后只读,可在多个goroutine间共享,适合每个请求都要使用schema的场景
+type CompiledSchema struct {
+	Lineschema        *Jsonschemaline
+	items             map[string]*JsonschemalineItem
+	gjsonPath         string
+	gjsonPathIgnoreID string
+	jsonSchema        []byte
+	transformer       *Transformer
+	patterns          validatePatterns
+}
+
+// Compile 编译已解析的lineschema,编译后不要再修改 l
+func Compile(l *Jsonschemaline) (compiled *CompiledSchema, err error) {
+	compiled = &CompiledSchema{
+		Lineschema: l,
+		items:      make(map[string]*JsonschemalineItem, len(l.Items)),
+	}
+	for _, item := range l.Items {
+		if _, ok := compiled.items[item.Fullname]; !ok { // 同 Unique,重复时以第一行为准
+			compiled.items[item.Fullname] = item
+		}
+	}
+	compiled.jsonSchema, err = l.JsonSchema()
+	if err != nil {
+		return nil, err
+	}
+	compiled.transformer, err = NewTransformer(l)
+	if err != nil {
+		return nil, err
+	}
+	compiled.gjsonPath = l.GjsonPathWithDefaultFormat(false)
+	compiled.gjsonPathIgnoreID = l.GjsonPathWithDefaultFormat(true)
+	compiled.patterns = compileValidatePatterns(l.Items)
+	return compiled, nil
+}
+
+// CompileLineschema 解析并编译lineschema
+func CompileLineschema(lineschema string) (compiled *CompiledSchema, err error) {
+	l, err := ParseJsonschemaline(lineschema)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(l)
+}
+
+// Item 按fullname查找行
+func (c *CompiledSchema) Item(fullname string) (item *JsonschemalineItem, ok bool) {
+	item, ok = c.items[fullname]
+	return item, ok
+}
+
+// JsonSchema 预先生成的json schema,返回副本
+func (c *CompiledSchema) JsonSchema() (jsonschemaByte []byte) {
+	return append([]byte(nil), c.jsonSchema...)
+}
+
+// GjsonPath 预先生成的 GjsonPathWithDefaultFormat 结果
+func (c *CompiledSchema) GjsonPath(ignoreID bool) (gjsonPath string) {
+	if ignoreID {
+		return c.gjsonPathIgnoreID
+	}
+	return c.gjsonPath
+}
+
+// Transform 使用预先生成的 Transformer 转换json数据
+func (c *CompiledSchema) Transform(input []byte) (output []byte, err error) {
+	return c.transformer.Transform(input)
+}
+
+// Validate 同 Jsonschemaline.Validate,使用预先编译的pattern
+func (c *CompiledSchema) Validate(data []byte) (violations Violations) {
+	return c.Lineschema.validate(data, c.patterns)
+}
+
+// CompiledSchemaCache 以 ID+version 为key缓存 CompiledSchema,可并发使用
+type CompiledSchemaCache struct {
+	lock    sync.RWMutex
+	schemas map[string]*CompiledSchema
+}
+
+func NewCompiledSchemaCache() (cache *CompiledSchemaCache) {
+	return &CompiledSchemaCache{
+		schemas: make(map[string]*CompiledSchema),
+	}
+}
+
+func compiledSchemaKey(id string, version string) (key string) {
+	return fmt.Sprintf("%s@%s", id, version)
+}
+
+// Get 获取缓存
+func (cache *CompiledSchemaCache) Get(id string, version string) (compiled *CompiledSchema, ok bool) {
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+	compiled, ok = cache.schemas[compiledSchemaKey(id, version)]
+	return compiled, ok
+}
+
+// Set 写入缓存,同 ID+version 覆盖
+func (cache *CompiledSchemaCache) Set(compiled *CompiledSchema) {
+	meta := compiled.Lineschema.Meta
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	cache.schemas[compiledSchemaKey(meta.ID, meta.Version)] = compiled
+}
+
+// GetOrCompile 缓存存在时直接返回,否则解析编译 lineschema 后写入缓存;
+// lineschema 的 id、version 必须和参数一致
+func (cache *CompiledSchemaCache) GetOrCompile(id string, version string, lineschema string) (compiled *CompiledSchema, err error) {
+	if compiled, ok := cache.Get(id, version); ok {
+		return compiled, nil
+	}
+	compiled, err = CompileLineschema(lineschema)
+	if err != nil {
+		return nil, err
+	}
+	meta := compiled.Lineschema.Meta
+	if meta.ID != id || meta.Version != version {
+		err = errors.Errorf("lineschema id=%s,version=%s not match cache key id=%s,version=%s", meta.ID, meta.Version, id, version)
+		return nil, err
+	}
+	key := compiledSchemaKey(id, version)
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if exists, ok := cache.schemas[key]; ok { // 并发编译时以先写入的为准
+		return exists, nil
+	}
+	cache.schemas[key] = compiled
+	return compiled, nil
+}
+
+// Delete 删除缓存
+func (cache *CompiledSchemaCache) Delete(id string, version string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	delete(cache.schemas, compiledSchemaKey(id, version))
+}
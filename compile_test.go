@@ -0,0 +1,149 @@
+package jsonschemaline_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+var compileLineschema = `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=pageSize,dst=Limit,format=int,required
+fullname=items[].id,dst=Fitems.#.Fid,format=int`
+
+func TestCompile(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(compileLineschema)
+	require.NoError(t, err)
+	compiled, err := jsonschemaline.Compile(lineschema)
+	require.NoError(t, err)
+
+	item, ok := compiled.Item("items[].id")
+	require.True(t, ok)
+	assert.Equal(t, "Fitems.#.Fid", item.Dst)
+	_, ok = compiled.Item("notExists")
+	assert.False(t, ok)
+
+	jsonschema, err := lineschema.JsonSchema()
+	require.NoError(t, err)
+	assert.Equal(t, string(jsonschema), string(compiled.JsonSchema()))
+	assert.Equal(t, lineschema.GjsonPathWithDefaultFormat(true), compiled.GjsonPath(true))
+	assert.Equal(t, lineschema.GjsonPathWithDefaultFormat(false), compiled.GjsonPath(false))
+
+	output, err := compiled.Transform([]byte(`{"pageSize":"10","items":[{"id":"1"}]}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Limit":10,"Fitems":[{"Fid":1}]}`, string(output))
+
+	modified := compiled.JsonSchema()
+	modified[0] = ' '
+	assert.Equal(t, string(jsonschema), string(compiled.JsonSchema()))
+}
+
+func TestCompiledSchemaCache(t *testing.T) {
+	version := "http://json-schema.org/draft-07/schema#"
+	cache := jsonschemaline.NewCompiledSchemaCache()
+	_, ok := cache.Get("list", version)
+	assert.False(t, ok)
+
+	var wg sync.WaitGroup
+	compileds := make([]*jsonschemaline.CompiledSchema, 8)
+	for i := range compileds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			compiled, err := cache.GetOrCompile("list", version, compileLineschema)
+			assert.NoError(t, err)
+			compileds[i] = compiled
+		}(i)
+	}
+	wg.Wait()
+	for _, compiled := range compileds {
+		assert.Same(t, compileds[0], compiled)
+	}
+
+	_, err := cache.GetOrCompile("other", version, compileLineschema)
+	assert.Error(t, err)
+
+	cache.Delete("list", version)
+	_, ok = cache.Get("list", version)
+	assert.False(t, ok)
+	cache.Set(compileds[0])
+	compiled, ok := cache.Get("list", version)
+	assert.True(t, ok)
+	assert.Same(t, compileds[0], compiled)
+}
+
+// largeLineschema 生成 n 行的lineschema,用于基准测试
+func largeLineschema(n int) (lineschema string) {
+	lines := []string{"version=http://json-schema.org/draft-07/schema#,direction=in,id=large"}
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("fullname=group%d.field%d,dst=Fgroup%d.Ffield%d,format=int,required,maximum=100,description=第%d个字段", i%10, i, i%10, i, i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func BenchmarkParseJsonschemaline(b *testing.B) {
+	lineschema := largeLineschema(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := jsonschemaline.ParseJsonschemaline(lineschema)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledSchemaCache(b *testing.B) {
+	lineschema := largeLineschema(500)
+	cache := jsonschemaline.NewCompiledSchemaCache()
+	version := "http://json-schema.org/draft-07/schema#"
+	if _, err := cache.GetOrCompile("large", version, lineschema); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := cache.GetOrCompile("large", version, lineschema)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJsonSchema(b *testing.B) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(largeLineschema(500))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := lineschema.JsonSchema()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledJsonSchema(b *testing.B) {
+	compiled, err := jsonschemaline.CompileLineschema(largeLineschema(500))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		compiled.JsonSchema()
+	}
+}
+
+func BenchmarkUnique(b *testing.B) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(largeLineschema(2000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lineschema.Items.Unique()
+	}
+}
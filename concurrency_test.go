@@ -0,0 +1,87 @@
+package jsonschemaline_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+var concurrencyLineschema = `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=pageSize,dst=Limit,format=int,required,maximum=100
+fullname=ids,dst=ids,type=array,format=int
+fullname=items[].id,dst=Fitems.#.Fid,format=int
+fullname=items[].tags[],dst=Fitems.#.Ftags.#`
+
+// generatorOutputs 调用全部生成方法,返回结果用于比较;可在子goroutine中调用,错误由调用方在测试goroutine中检查
+func generatorOutputs(lineschema *jsonschemaline.Jsonschemaline) (outputs []string, err error) {
+	jsonschema, err := lineschema.JsonSchema()
+	if err != nil {
+		return nil, err
+	}
+	example, err := lineschema.JsonExample()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := lineschema.MarshalDocument()
+	if err != nil {
+		return nil, err
+	}
+	coerced, err := lineschema.Coerce([]byte(`{"pageSize":"20","ids":["1"]}`))
+	if err != nil {
+		return nil, err
+	}
+	instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+	inverse, _ := lineschema.Inverse()
+	structs := lineschema.ToSturct()
+	outputs = []string{
+		lineschema.String(),
+		string(jsonschema),
+		example,
+		string(doc),
+		string(coerced),
+		lineschema.GjsonPathWithDefaultFormat(false),
+		lineschema.GjsonPathWithDefaultFormat(true),
+		structs.Json(),
+		instructTpl.String(),
+		inverse.String(),
+		lineschema.Validate([]byte(`{"pageSize":"200"}`)).Error(),
+	}
+	return outputs, nil
+}
+
+func TestGeneratorsDoNotMutate(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(concurrencyLineschema)
+	require.NoError(t, err)
+	first, err := generatorOutputs(lineschema)
+	require.NoError(t, err)
+	second, err := generatorOutputs(lineschema)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, concurrencyLineschema, lineschema.String())
+}
+
+// TestGeneratorsConcurrent 配合 go test -race 使用
+func TestGeneratorsConcurrent(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(concurrencyLineschema)
+	require.NoError(t, err)
+	expected, err := generatorOutputs(lineschema)
+	require.NoError(t, err)
+	count := 16
+	outputs, errs := make([][]string, count), make([]error, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputs[i], errs[i] = generatorOutputs(lineschema)
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < count; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, expected, outputs[i])
+	}
+}
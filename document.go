@@ -0,0 +1,190 @@
+package jsonschemaline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/suifengpiao14/kvstruct"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"gopkg.in/yaml.v3"
+)
+
+// documentArrayKeys 值为json数组时,按数组输出的字段
+var documentArrayKeys = []string{"enum", "enumNames", "examples"}
+
+// MarshalDocument 输出结构化的json文档,格式如下:
+//
+//	{"meta":{"version":"...","direction":"in","id":"..."},"items":[{"fullname":"...","dst":"...","type":"string","required":true,"maxLength":10,"enum":["1","2"]}]}
+//
+// items 中字段与 JsonschemalineItem 一致,按 jsonschemalineItemOrder 排序,src、dst 全部显式输出;
+// 数值字段输出数字,布尔字段输出布尔值,enum、enumNames、examples 为json数组时原样输出。注释只存在于文本形式,不输出
+func (l *Jsonschemaline) MarshalDocument() (doc []byte, err error) {
+	doc = []byte(`{"meta":{},"items":[]}`)
+	if l.Meta != nil {
+		for _, kv := range [][2]string{{"version", l.Meta.Version}, {"direction", l.Meta.Direction}, {"id", l.Meta.ID}} {
+			doc, err = sjson.SetBytes(doc, fmt.Sprintf("meta.%s", kv[0]), kv[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	kinds := documentFieldKinds()
+	for _, item := range l.Items {
+		keywords := itemKeywords(item, nil)
+		itemDoc := []byte(`{}`)
+		for _, k := range documentKeyOrder(keywords) {
+			v := keywords[k]
+			var raw []byte
+			switch kinds[k] {
+			case reflect.Bool, reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+				raw = []byte(v)
+			default:
+				if inArray(k, documentArrayKeys) && strings.HasPrefix(v, "[") && gjson.Valid(v) {
+					raw = []byte(v)
+				} else {
+					raw, _ = json.Marshal(v)
+				}
+			}
+			itemDoc, err = sjson.SetRawBytes(itemDoc, escapeDocumentKey(k), raw)
+			if err != nil {
+				return nil, err
+			}
+		}
+		doc, err = sjson.SetRawBytes(doc, "items.-1", itemDoc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// MarshalDocumentYaml 输出结构化的yaml文档,字段顺序与 MarshalDocument 一致
+func (l *Jsonschemaline) MarshalDocumentYaml() (doc []byte, err error) {
+	jsonDoc, err := l.MarshalDocument()
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	err = yaml.Unmarshal(jsonDoc, &node)
+	if err != nil {
+		return nil, err
+	}
+	resetYamlStyle(&node)
+	var w bytes.Buffer
+	encoder := yaml.NewEncoder(&w)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&node)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+// ParseJsonschemalineDocument 解析 MarshalDocument 格式的json文档
+func ParseJsonschemalineDocument(doc []byte) (jsonline *Jsonschemaline, err error) {
+	if !gjson.ValidBytes(doc) {
+		err = errors.New("invalid lineschema json document")
+		return nil, err
+	}
+	result := gjson.ParseBytes(doc)
+	meta, err := parseMeta(documentKVS(result.Get("meta")))
+	if err != nil {
+		return nil, errors.WithMessage(err, "meta")
+	}
+	jsonline = &Jsonschemaline{
+		Meta:  meta,
+		Items: make(JsonschemalineItems, 0),
+	}
+	for i, itemResult := range result.Get("items").Array() {
+		item, err := parseItem(documentKVS(itemResult))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "items.%d", i)
+		}
+		item.Lineschema = jsonline
+		jsonline.Items = append(jsonline.Items, item)
+	}
+	return jsonline, nil
+}
+
+// documentKVS 文档中的对象转换为一行lineschema的key=value,与文本形式使用相同的解析流程
+func documentKVS(result gjson.Result) (kvs kvstruct.KVS) {
+	pairs := make([]linePair, 0)
+	result.ForEach(func(key, value gjson.Result) bool {
+		pairs = append(pairs, linePair{Key: key.String(), Value: jsonSchemaValue2String(value)})
+		return true
+	})
+	return pairs2kvs(pairs)
+}
+
+// ParseJsonschemalineDocumentYaml 解析 MarshalDocumentYaml 格式的yaml文档
+func ParseJsonschemalineDocumentYaml(doc []byte) (jsonline *Jsonschemaline, err error) {
+	var data interface{}
+	err = yaml.Unmarshal(doc, &data)
+	if err != nil {
+		return nil, err
+	}
+	jsonDoc, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJsonschemalineDocument(jsonDoc)
+}
+
+// documentFieldKinds JsonschemalineItem 各字段json名称对应的类型
+func documentFieldKinds() (kinds map[string]reflect.Kind) {
+	kinds = make(map[string]reflect.Kind)
+	rt := reflect.TypeOf(JsonschemalineItem{})
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := strings.TrimSpace(strings.Split(field.Tag.Get("json"), ",")[0])
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr { // 数值约束为指针,nil 表示未设置
+			fieldType = fieldType.Elem()
+		}
+		kinds[name] = fieldType.Kind()
+	}
+	return kinds
+}
+
+// documentKeyOrder 按 jsonschemalineItemOrder 排序,不在其中的key按字母序排在最后
+func documentKeyOrder(keywords map[string]string) (keys []string) {
+	keys = make([]string, 0, len(keywords))
+	for _, k := range jsonschemalineItemOrder {
+		if _, ok := keywords[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	others := make([]string, 0)
+	for k := range keywords {
+		if !inArray(k, jsonschemalineItemOrder) {
+			others = append(others, k)
+		}
+	}
+	sort.Strings(others)
+	return append(keys, others...)
+}
+
+func escapeDocumentKey(key string) (escaped string) {
+	return ReplacePathSpecalChar(strings.ReplaceAll(key, ".", `\.`))
+}
+
+// resetYamlStyle json 解析得到的节点为flow风格,重置为默认的block风格
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
@@ -0,0 +1,76 @@
+package jsonschemaline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestMarshalDocument(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=pageSize,dst=Limit,format=number,required,title="Page size",minimum=0,maxLength=3
+fullname=status,dst=status,enum=["1","2"],enumNames=["启用","禁用"]`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+
+	t.Run("json", func(t *testing.T) {
+		doc, err := lineschema.MarshalDocument()
+		require.NoError(t, err)
+		expected := `{"meta":{"version":"http://json-schema.org/draft-07/schema#","direction":"in","id":"list"},"items":[{"fullname":"pageSize","src":"pageSize","dst":"Limit","type":"string","format":"number","required":true,"title":"Page size","minimum":0,"maxLength":3},{"fullname":"status","src":"status","dst":"status","type":"string","enum":["1","2"],"enumNames":["启用","禁用"]}]}`
+		assert.Equal(t, expected, string(doc))
+
+		back, err := jsonschemaline.ParseJsonschemalineDocument(doc)
+		require.NoError(t, err)
+		assert.Equal(t, line, back.String())
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		doc, err := lineschema.MarshalDocumentYaml()
+		require.NoError(t, err)
+		expected := `meta:
+  version: http://json-schema.org/draft-07/schema#
+  direction: in
+  id: list
+items:
+  - fullname: pageSize
+    src: pageSize
+    dst: Limit
+    type: string
+    format: number
+    required: true
+    title: Page size
+    minimum: 0
+    maxLength: 3
+  - fullname: status
+    src: status
+    dst: status
+    type: string
+    enum:
+      - "1"
+      - "2"
+    enumNames:
+      - 启用
+      - 禁用
+`
+		assert.Equal(t, expected, string(doc))
+
+		back, err := jsonschemaline.ParseJsonschemalineDocumentYaml(doc)
+		require.NoError(t, err)
+		assert.Equal(t, line, back.String())
+	})
+}
+
+func TestParseJsonschemalineDocumentDefaults(t *testing.T) {
+	doc := `{"meta":{"version":"http://json-schema.org/draft-07/schema#","direction":"in","id":"list"},"items":[{"fullname":"items[].id","dst":"Fitems.#.Fid","format":"int"}]}`
+	fromDoc, err := jsonschemaline.ParseJsonschemalineDocument([]byte(doc))
+	require.NoError(t, err)
+	fromText, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=in,id=list\nfullname=items[].id,dst=Fitems.#.Fid,format=int")
+	require.NoError(t, err)
+	assert.Equal(t, "items.#.id", fromDoc.Items[0].Src)
+	assert.Equal(t, fromText.String(), fromDoc.String())
+
+	_, err = jsonschemaline.ParseJsonschemalineDocument([]byte(`{"meta":{"direction":"in"},"items":[]}`))
+	require.Error(t, err)
+}
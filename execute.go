@@ -0,0 +1,181 @@
+package jsonschemaline
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ExecuteContext InstructTpl 执行时模板中的 . ,从input读取数据,写入output。
+// 路径以自身ID开头时(如 mainIn.pageIndex),去掉"ID."前缀后读写
+type ExecuteContext struct {
+	ID     string
+	input  gjson.Result
+	output []byte
+}
+
+// NewExecuteContext 生成执行上下文,input 为json数据
+func NewExecuteContext(id string, input []byte) (ctx *ExecuteContext, err error) {
+	if !gjson.ValidBytes(input) {
+		err = errors.Errorf("invalid json: %s", string(input))
+		return nil, err
+	}
+	ctx = &ExecuteContext{
+		ID:    id,
+		input: gjson.ParseBytes(input),
+	}
+	return ctx, nil
+}
+
+// Output 执行结果,未写入任何数据时为 {}
+func (ctx *ExecuteContext) Output() (output []byte) {
+	if len(ctx.output) == 0 {
+		return []byte("{}")
+	}
+	return ctx.output
+}
+
+// Get 读取input,优先使用去掉"ID."前缀的路径
+func (ctx *ExecuteContext) Get(path string) (value gjson.Result) {
+	if trimmed := ctx.trimID(path); trimmed != path {
+		if value = ctx.input.Get(trimmed); value.Exists() {
+			return value
+		}
+	}
+	return ctx.input.Get(path)
+}
+
+// Set 写入output,value 为空的list、dict且路径已存在时不覆盖(用于声明上级对象、数组)
+func (ctx *ExecuteContext) Set(path string, value interface{}) (err error) {
+	path = ctx.trimID(path)
+	if path == "" {
+		return nil
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		if len(v) == 0 && gjson.GetBytes(ctx.output, path).Exists() {
+			return nil
+		}
+	case map[string]interface{}:
+		if len(v) == 0 && gjson.GetBytes(ctx.output, path).Exists() {
+			return nil
+		}
+	}
+	ctx.output, err = sjson.SetBytes(ctx.output, path, value)
+	return err
+}
+
+func (ctx *ExecuteContext) trimID(path string) (trimmed string) {
+	if path == ctx.ID {
+		return ""
+	}
+	return strings.TrimPrefix(path, fmt.Sprintf("%s.", ctx.ID))
+}
+
+// getSet 按 src→dst 复制数据,支持 .# 数组路径,coerce 不为空时转换字符串值
+func (ctx *ExecuteContext) getSet(dst string, src string, coerce func(value string) (coerced interface{}, err error)) (err error) {
+	mapping := transformMapping{
+		fullname: dst,
+		srcParts: strings.Split(src, ".#"),
+		dstParts: strings.Split(ctx.trimID(dst), ".#"),
+		coerce:   coerce,
+	}
+	if len(mapping.srcParts) != len(mapping.dstParts) {
+		err = errors.Errorf("src %s and dst %s must have the same number of .#", src, dst)
+		return err
+	}
+	root := ctx.input
+	if trimmed := ctx.trimID(src); trimmed != src && ctx.input.Get(strings.Split(trimmed, ".#")[0]).Exists() {
+		mapping.srcParts = strings.Split(trimmed, ".#")
+	}
+	violations := make(Violations, 0)
+	ctx.output, err = mapping.transform(root, ctx.output, "", mapping.srcParts, mapping.dstParts, &violations)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// FuncMap ParseInstructTp 生成的模板用到的函数,模板中的 . 为 *ExecuteContext。
+// 每次调用返回新的map,调用方可自由增删,不影响其它调用
+func FuncMap() (funcMap template.FuncMap) {
+	return template.FuncMap{
+		"getValue": func(ctx *ExecuteContext, path string) interface{} {
+			return ctx.Get(path).Value()
+		},
+		"setValue": func(ctx *ExecuteContext, path string, value interface{}) (string, error) {
+			return "", ctx.Set(path, value)
+		},
+		"getSetValue": func(ctx *ExecuteContext, dst string, src string) (string, error) {
+			return "", ctx.getSet(dst, src, nil)
+		},
+		"getSetNumber": func(ctx *ExecuteContext, dst string, src string) (string, error) {
+			number, _ := GetFormat("float") // int、number、float 共用,按浮点数转换,整数输出不变
+			return "", ctx.getSet(dst, src, number.Coerce)
+		},
+		"list": func(values ...interface{}) []interface{} {
+			return append(make([]interface{}, 0), values...)
+		},
+		"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+			if len(pairs)%2 != 0 {
+				return nil, errors.Errorf("dict requires key value pairs,got:%d arguments", len(pairs))
+			}
+			m := make(map[string]interface{})
+			for i := 0; i < len(pairs); i += 2 {
+				m[cast.ToString(pairs[i])] = pairs[i+1]
+			}
+			return m, nil
+		},
+		"mul": func(a interface{}, b interface{}) (interface{}, error) {
+			x, err := cast.ToFloat64E(a)
+			if err != nil {
+				return nil, err
+			}
+			y, err := cast.ToFloat64E(b)
+			if err != nil {
+				return nil, err
+			}
+			product := x * y
+			if product == math.Trunc(product) && math.Abs(product) < math.MaxInt64 {
+				return int64(product), nil
+			}
+			return product, nil
+		},
+	}
+}
+
+// Execute 以input为数据执行 instructTpl.String() 生成的模板,返回写入的json
+func Execute(instructTpl *InstructTpl, input []byte) (output []byte, err error) {
+	return ExecuteWithFuncs(instructTpl, input, nil)
+}
+
+// ExecuteWithFuncs 同 Execute,funcs 为本次执行追加的模板函数,与 FuncMap 中同名时覆盖
+func ExecuteWithFuncs(instructTpl *InstructTpl, input []byte, funcs template.FuncMap) (output []byte, err error) {
+	funcMap := FuncMap()
+	for name, fn := range funcs {
+		funcMap[name] = fn
+	}
+	tpl, err := template.New(instructTpl.ID).Funcs(funcMap).Parse(instructTpl.String())
+	if err != nil {
+		return nil, err
+	}
+	ctx, err := NewExecuteContext(instructTpl.ID, input)
+	if err != nil {
+		return nil, err
+	}
+	err = tpl.ExecuteTemplate(io.Discard, instructTpl.ID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Output(), nil
+}
@@ -0,0 +1,108 @@
+package jsonschemaline
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Format format 的行为定义,注册后 Validate、Coerce、ToSturct、GjsonPathWithDefaultFormat、ParseInstructTp、JsonExample 均按此处理
+type Format struct {
+	Name          string
+	Validate      func(value string) (ok bool)                        // 校验字符串形式的值,为空时不校验
+	IsNumber      bool                                                // 数值类型,转换后参与 maximum、minimum、multipleOf 校验
+	GoType        string                                              // 生成go结构体时的类型,为空时使用type
+	GjsonModifier string                                              // 入参按format转换时追加的gjson modifier,如 @tonum
+	InstructCmd   string                                              // ParseInstructTp 生成的指令,为空时为 getSetValue
+	Example       func() (example string)                             // 生成案例值,为空时使用type的默认值
+	Coerce        func(value string) (coerced interface{}, err error) // Coerce 时将字符串转换为实际类型,为空时保持字符串
+}
+
+var (
+	formatRegistry     = make(map[string]Format)
+	formatRegistryLock sync.RWMutex
+)
+
+// RegisterFormat 注册format,同名覆盖
+func RegisterFormat(formats ...Format) {
+	formatRegistryLock.Lock()
+	defer formatRegistryLock.Unlock()
+	for _, format := range formats {
+		formatRegistry[format.Name] = format
+	}
+}
+
+// UnregisterFormat 删除已注册的format
+func UnregisterFormat(names ...string) {
+	formatRegistryLock.Lock()
+	defer formatRegistryLock.Unlock()
+	for _, name := range names {
+		delete(formatRegistry, name)
+	}
+}
+
+// GetFormat 获取已注册的format
+func GetFormat(name string) (format Format, ok bool) {
+	formatRegistryLock.RLock()
+	defer formatRegistryLock.RUnlock()
+	format, ok = formatRegistry[name]
+	return format, ok
+}
+
+// formatByGoType 获取go类型对应的已注册format,多个时按名称取第一个,如 int 对应 int、integer、number 时取 int
+func formatByGoType(goType string) (format Format, ok bool) {
+	formatRegistryLock.RLock()
+	defer formatRegistryLock.RUnlock()
+	for name, f := range formatRegistry {
+		if f.GoType == goType && (!ok || name < format.Name) {
+			format, ok = f, true
+		}
+	}
+	return format, ok
+}
+
+func init() {
+	validateInt := func(value string) bool {
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	}
+	validateFloat := func(value string) bool {
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	}
+	validateBool := func(value string) bool {
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	}
+	validateTime := func(layout string) func(value string) bool {
+		return func(value string) bool {
+			_, err := time.Parse(layout, value)
+			return err == nil
+		}
+	}
+	coerceInt := func(value string) (interface{}, error) {
+		return strconv.ParseInt(value, 10, 64)
+	}
+	coerceFloat := func(value string) (interface{}, error) {
+		return strconv.ParseFloat(value, 64)
+	}
+	coerceBool := func(value string) (interface{}, error) {
+		return strconv.ParseBool(value)
+	}
+	emailReg := regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	example := func(value string) func() string {
+		return func() string { return value }
+	}
+	RegisterFormat(
+		Format{Name: "int", Validate: validateInt, IsNumber: true, GoType: "int", GjsonModifier: "@tonum", InstructCmd: "getSetNumber", Example: example("0"), Coerce: coerceInt},
+		Format{Name: "integer", Validate: validateInt, IsNumber: true, GoType: "int", GjsonModifier: "@tonum", InstructCmd: "getSetNumber", Example: example("0"), Coerce: coerceInt},
+		Format{Name: "number", Validate: validateInt, IsNumber: true, GoType: "int", GjsonModifier: "@tonum", InstructCmd: "getSetNumber", Example: example("0"), Coerce: coerceInt}, // 与生成的go类型一致,按整数处理,小数使用 float
+		Format{Name: "float", Validate: validateFloat, IsNumber: true, GoType: "float64", GjsonModifier: "@tonum", InstructCmd: "getSetNumber", Example: example("0.0"), Coerce: coerceFloat},
+		Format{Name: "bool", Validate: validateBool, GoType: "bool", GjsonModifier: "@tobool", Example: example("false"), Coerce: coerceBool},
+		Format{Name: "boolean", Validate: validateBool, GoType: "bool", GjsonModifier: "@tobool", Example: example("false"), Coerce: coerceBool},
+		Format{Name: "date-time", Validate: validateTime(time.RFC3339), Example: example("2006-01-02T15:04:05Z")},
+		Format{Name: "date", Validate: validateTime("2006-01-02"), Example: example("2006-01-02")},
+		Format{Name: "email", Validate: emailReg.MatchString, Example: example("user@example.com")},
+	)
+}
@@ -0,0 +1,86 @@
+package jsonschemaline_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestRegisterFormat(t *testing.T) {
+	mobileReg := regexp.MustCompile(`^1[3-9]\d{9}$`)
+	jsonschemaline.RegisterFormat(jsonschemaline.Format{
+		Name:          "mobile",
+		Validate:      mobileReg.MatchString,
+		GoType:        "Mobile",
+		GjsonModifier: "@tostring",
+		InstructCmd:   "getSetMobile",
+		Example:       func() string { return "13800138000" },
+	})
+	t.Cleanup(func() { jsonschemaline.UnregisterFormat("mobile") })
+	format, ok := jsonschemaline.GetFormat("mobile")
+	require.True(t, ok)
+	assert.Equal(t, "Mobile", format.GoType)
+
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=user
+fullname=phone,dst=phone,format=mobile,required
+fullname=age,dst=age,format=int`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+
+	t.Run("validate", func(t *testing.T) {
+		assert.Empty(t, lineschema.Validate([]byte(`{"phone":"13800138000","age":"18"}`)))
+		violations := lineschema.Validate([]byte(`{"phone":"12345","age":"x"}`))
+		require.Len(t, violations, 2)
+		assert.Equal(t, jsonschemaline.VALIDATE_ERROR_CODE_FORMAT, violations[0].Code)
+		assert.Equal(t, jsonschemaline.VALIDATE_ERROR_CODE_FORMAT, violations[1].Code)
+	})
+
+	t.Run("gjson path", func(t *testing.T) {
+		path := lineschema.GjsonPathWithDefaultFormat(false)
+		assert.Contains(t, path, "phone:phone.@tostring")
+		assert.Contains(t, path, "age:age.@tonum")
+	})
+
+	t.Run("instruct", func(t *testing.T) {
+		instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+		require.Len(t, instructTpl.Instructs, 2)
+		assert.Equal(t, "getSetMobile", instructTpl.Instructs[0].Cmd)
+		assert.Equal(t, "getSetNumber", instructTpl.Instructs[1].Cmd)
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		structs := lineschema.ToSturct()
+		attr, ok := structs[0].GetAttr("Phone")
+		require.True(t, ok)
+		assert.Equal(t, "Mobile", attr.Type)
+	})
+
+	t.Run("example", func(t *testing.T) {
+		example, err := lineschema.JsonExample()
+		require.NoError(t, err)
+		assert.Equal(t, `{"phone":"13800138000","age":"0"}`, example)
+	})
+}
+
+func TestFormatNumber(t *testing.T) {
+	format, ok := jsonschemaline.GetFormat("number")
+	require.True(t, ok)
+	assert.Equal(t, "int", format.GoType)
+	assert.True(t, format.Validate("12"))
+	assert.False(t, format.Validate("1.5"))
+	coerced, err := format.Coerce("12")
+	require.NoError(t, err)
+	assert.IsType(t, int64(0), coerced)
+
+	lineschema, err := jsonschemaline.Json2lineSchema(`{"id":12,"price":1.5,"valid":true}`)
+	require.NoError(t, err)
+	formats := make(map[string]string)
+	for _, item := range lineschema.Items {
+		formats[item.Fullname] = fmt.Sprintf("%s:%s", item.Format, item.Example)
+	}
+	assert.Equal(t, map[string]string{"id": "int:12", "price": "float:1.5", "valid": "bool:true"}, formats)
+}
@@ -0,0 +1,478 @@
+package jsonschemaline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// goSourceBasicTypes json schema 类型对应的go类型
+var goSourceBasicTypes = map[string]string{
+	"":        "interface{}",
+	"null":    "interface{}",
+	"integer": "int",
+	"number":  "float64",
+	"boolean": "bool",
+	"object":  "map[string]interface{}",
+	"array":   "[]interface{}",
+}
+
+// goSourceImports 类型中包名对应的导入路径
+var goSourceImports = map[string]string{
+	"time": "time",
+	"json": "encoding/json",
+}
+
+// GoSource 生成 gofmt 后的go源码,可用于 go:generate:
+//   - root 结构体排在最前,其余按名称排序,输出稳定
+//   - Title、Description、Comment 生成字段注释
+//   - Struct.Lineschema 生成常量 {Name}Lineschema
+func (s Structs) GoSource(pkgName string) (source []byte, err error) {
+	if !token.IsIdentifier(pkgName) {
+		err = errors.Errorf("invalid package name: %s", pkgName)
+		return nil, err
+	}
+	structs := make(Structs, len(s))
+	copy(structs, s)
+	sort.SliceStable(structs, func(i, j int) bool {
+		if structs[i].IsRoot != structs[j].IsRoot {
+			return structs[i].IsRoot
+		}
+		return structs[i].Name < structs[j].Name
+	})
+
+	imports := make(map[string]bool)
+	structsByName := make(map[string]*Struct, len(structs))
+	for _, struc := range structs {
+		structsByName[struc.Name] = struc
+	}
+	var body bytes.Buffer
+	for _, struc := range structs {
+		if struc.Lineschema != "" {
+			fmt.Fprintf(&body, "// %sLineschema lineschema of %s\n", struc.Name, struc.Name)
+			fmt.Fprintf(&body, "const %sLineschema = %s\n\n", struc.Name, goSourceString(struc.Lineschema))
+		}
+		decl, err := goSourceTypeDecl(struc, imports)
+		if err != nil {
+			return nil, err
+		}
+		body.WriteString(decl)
+		if struc.ValidateMethod {
+			validate, err := goSourceValidate(struc, structsByName, imports)
+			if err != nil {
+				return nil, err
+			}
+			body.WriteString(validate)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by jsonschemaline. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	if len(imports) > 0 {
+		groups := [2][]string{} // 标准库在前,第三方在后
+		for path := range imports {
+			if !strings.Contains(path, `"`) {
+				path = strconv.Quote(path)
+			}
+			importPath := path[strings.Index(path, `"`):]
+			group := 0
+			if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+				group = 1
+			}
+			groups[group] = append(groups[group], path)
+		}
+		buf.WriteString("import (\n")
+		for i, group := range groups {
+			sort.Slice(group, func(a, b int) bool {
+				return group[a][strings.Index(group[a], `"`):] < group[b][strings.Index(group[b], `"`):]
+			})
+			if i > 0 && len(group) > 0 && len(groups[0]) > 0 {
+				buf.WriteString("\n")
+			}
+			for _, path := range group {
+				fmt.Fprintf(&buf, "%s\n", path)
+			}
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.Write(body.Bytes())
+	source, err = format.Source(buf.Bytes())
+	if err != nil {
+		err = errors.WithMessage(err, "format go source")
+		return nil, err
+	}
+	return source, nil
+}
+
+// goSourceTypeDecl 生成类型定义,Type 不为空时为数组类型
+func goSourceTypeDecl(struc *Struct, imports map[string]bool) (decl string, err error) {
+	var w bytes.Buffer
+	if struc.Type != "" {
+		typ, err := goSourceType(struc.Type, struc.Imports, imports)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&w, "type %s %s\n\n", struc.Name, typ)
+		return w.String(), nil
+	}
+	fmt.Fprintf(&w, "type %s struct {\n", struc.Name)
+	for _, attr := range struc.Attrs {
+		typ, err := goSourceType(attr.Type, struc.Imports, imports)
+		if err != nil {
+			return "", err
+		}
+		for _, comment := range attr.docLines() {
+			fmt.Fprintf(&w, "// %s\n", comment)
+		}
+		tag := ""
+		if attr.Tag != "" {
+			tag = fmt.Sprintf("`%s`", attr.Tag)
+		}
+		fmt.Fprintf(&w, "%s %s %s\n", attr.Name, typ, tag)
+	}
+	w.WriteString("}\n\n")
+	return w.String(), nil
+}
+
+// docLines 字段注释,Title、Description、Comment 去重后按行输出
+func (attr StructAttr) docLines() (lines []string) {
+	lines = make([]string, 0)
+	for _, text := range appendUnique(nil, attr.Title, attr.Description, attr.Comment) {
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// goSourceQualifiedIdent 类型中引用其它包的标识符,如 decimal.Decimal
+var goSourceQualifiedIdent = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\.[A-Za-z_]`)
+
+// goSourceType 将 *、[] 修饰的json schema类型转换为go类型,记录需要导入的包,包路径优先从 knownImports 中查找
+func goSourceType(typ string, knownImports map[string]string, imports map[string]bool) (goType string, err error) {
+	base := strings.TrimLeft(typ, "*[]")
+	modifier := typ[:len(typ)-len(base)]
+	if basic, ok := goSourceBasicTypes[base]; ok {
+		base = basic
+	}
+	for _, match := range goSourceQualifiedIdent.FindAllStringSubmatch(base, -1) {
+		pkg := match[1]
+		path, ok := knownImports[pkg]
+		if !ok {
+			path, ok = goSourceImports[pkg]
+		}
+		if !ok {
+			err = errors.Errorf("unknown package %s in type %s", pkg, typ)
+			return "", err
+		}
+		if goSourcePkgName(path) != pkg { // 包名和路径不一致时使用别名
+			path = fmt.Sprintf("%s %s", pkg, strconv.Quote(path))
+		}
+		imports[path] = true
+	}
+	return modifier + base, nil
+}
+
+// goSourcePkgName 按导入路径推断包名,忽略 /v2、.v3 形式的版本后缀
+func goSourcePkgName(path string) (pkg string) {
+	parts := strings.Split(path, "/")
+	pkg = parts[len(parts)-1]
+	if len(parts) > 1 && goSourceMajorVersion.MatchString(pkg) {
+		pkg = parts[len(parts)-2]
+	}
+	if index := strings.LastIndex(pkg, ".v"); index > 0 && goSourceMajorVersion.MatchString(pkg[index+1:]) {
+		pkg = pkg[:index]
+	}
+	return pkg
+}
+
+var goSourceMajorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// goSourceString 生成字符串字面量,优先使用原始字符串
+func goSourceString(s string) (literal string) {
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("`%s`", s)
+}
+
+// goSourceValidate 生成 Validate() error 方法,校验 StructAttr.Rule 中的约束,并递归校验同样生成了 Validate 的子结构体
+func goSourceValidate(struc *Struct, structsByName map[string]*Struct, imports map[string]bool) (source string, err error) {
+	hasValidate := func(typ string) bool {
+		sub, ok := structsByName[strings.TrimPrefix(typ, "*")]
+		return ok && sub.ValidateMethod
+	}
+	var vars, w bytes.Buffer
+	fmt.Fprintf(&w, "// Validate checks the constraints declared in lineschema\nfunc (s %s) Validate() error {\n", struc.Name)
+	if struc.Type != "" {
+		if elem := strings.TrimPrefix(struc.Type, "[]"); elem != struc.Type && hasValidate(elem) {
+			imports["fmt"] = true
+			w.WriteString("for i, item := range s {\n")
+			if strings.HasPrefix(elem, "*") {
+				w.WriteString("if item == nil {\ncontinue\n}\n")
+			}
+			w.WriteString("if err := item.Validate(); err != nil {\nreturn fmt.Errorf(\"%d.%w\", i, err)\n}\n}\n")
+		}
+		w.WriteString("return nil\n}\n\n")
+		return w.String(), nil
+	}
+	for _, attr := range struc.Attrs {
+		typ, err := goSourceType(attr.Type, struc.Imports, imports)
+		if err != nil {
+			return "", err
+		}
+		rule := attr.Rule
+		if rule == nil {
+			rule = &StructAttrRule{}
+		}
+		v := validateAttr{
+			name:    attr.jsonName(),
+			field:   fmt.Sprintf("s.%s", attr.Name),
+			isPtr:   strings.HasPrefix(typ, "*"),
+			base:    strings.TrimPrefix(typ, "*"),
+			rule:    rule,
+			imports: imports,
+		}
+		v.expr = v.field
+		if v.isPtr {
+			v.expr = fmt.Sprintf("*%s", v.field)
+		}
+		checks := make([]string, 0)
+		zero := ""
+		switch sub, isSub := structsByName[v.base]; {
+		case v.base == "string":
+			zero = `""`
+			if checks, err = v.stringChecks(&vars, fmt.Sprintf("validatePattern%s%s", struc.Name, attr.Name)); err != nil {
+				return "", err
+			}
+		case goNumberTypes[v.base]:
+			zero = "0"
+			if checks, err = v.numberChecks(); err != nil {
+				return "", err
+			}
+		case strings.HasPrefix(v.base, "[]"), isSub && sub.Type != "":
+			zero = "nil"
+			checks = v.itemsChecks()
+			elem := strings.TrimPrefix(v.base, "[]")
+			switch {
+			case elem == v.base && hasValidate(v.base):
+				checks = append(checks, v.nestedCheck())
+			case elem != v.base && hasValidate(elem):
+				loop := fmt.Sprintf("for i, item := range %s {\n", v.expr)
+				if strings.HasPrefix(elem, "*") {
+					loop += "if item == nil {\ncontinue\n}\n"
+				}
+				loop += fmt.Sprintf("if err := item.Validate(); err != nil {\nreturn fmt.Errorf(\"%s.%%d.%%w\", i, err)\n}\n}\n", v.escapedName())
+				checks = append(checks, loop)
+			}
+		case strings.HasPrefix(v.base, "map["):
+			zero = "nil"
+		case hasValidate(v.base):
+			checks = append(checks, v.nestedCheck())
+		}
+		if rule.Required && (v.isPtr || zero == `""` || zero == "nil") {
+			nilValue := zero
+			if v.isPtr {
+				nilValue = "nil"
+			}
+			fmt.Fprintf(&w, "if %s == %s {\n%s\n}\n", v.field, nilValue, v.fail("required"))
+		}
+		if len(checks) == 0 {
+			continue
+		}
+		guard := ""
+		switch {
+		case v.isPtr:
+			guard = fmt.Sprintf("%s != nil", v.field)
+		case !rule.Required && zero != "": // 非必填的零值视为未传
+			guard = fmt.Sprintf("%s != %s", v.field, zero)
+		}
+		if guard != "" {
+			fmt.Fprintf(&w, "if %s {\n", guard)
+		}
+		w.WriteString(strings.Join(checks, ""))
+		if guard != "" {
+			w.WriteString("}\n")
+		}
+	}
+	w.WriteString("return nil\n}\n\n")
+	return vars.String() + w.String(), nil
+}
+
+// validateAttr 生成单个字段校验代码,expr 为取值表达式(指针已解引用),field 为字段本身
+type validateAttr struct {
+	name    string
+	field   string
+	expr    string
+	isPtr   bool
+	base    string
+	rule    *StructAttrRule
+	imports map[string]bool
+}
+
+func (v validateAttr) escapedName() string {
+	return strings.ReplaceAll(v.name, "%", "%%")
+}
+
+// fail 返回错误的语句
+func (v validateAttr) fail(msg string) string {
+	v.imports["errors"] = true
+	return fmt.Sprintf("return errors.New(%s)", strconv.Quote(fmt.Sprintf("%s: %s", v.name, msg)))
+}
+
+func (v validateAttr) check(cond string, msg string) string {
+	return fmt.Sprintf("if %s {\n%s\n}\n", cond, v.fail(msg))
+}
+
+func (v validateAttr) nestedCheck() string {
+	v.imports["fmt"] = true
+	return fmt.Sprintf("if err := %s.Validate(); err != nil {\nreturn fmt.Errorf(\"%s.%%w\", err)\n}\n", v.field, v.escapedName())
+}
+
+func (v validateAttr) stringChecks(vars *bytes.Buffer, patternVar string) (checks []string, err error) {
+	checks = make([]string, 0)
+	rule := v.rule
+	if rule.MinLength > 0 || rule.MaxLength > 0 {
+		v.imports["unicode/utf8"] = true
+	}
+	if rule.MinLength > 0 {
+		checks = append(checks, v.check(fmt.Sprintf("utf8.RuneCountInString(%s) < %d", v.expr, rule.MinLength), fmt.Sprintf("length must be >= %d", rule.MinLength)))
+	}
+	if rule.MaxLength > 0 {
+		checks = append(checks, v.check(fmt.Sprintf("utf8.RuneCountInString(%s) > %d", v.expr, rule.MaxLength), fmt.Sprintf("length must be <= %d", rule.MaxLength)))
+	}
+	if rule.Pattern != "" {
+		if _, err = regexp.Compile(rule.Pattern); err != nil {
+			err = errors.WithMessagef(err, "%s pattern", v.name)
+			return nil, err
+		}
+		v.imports["regexp"] = true
+		fmt.Fprintf(vars, "var %s = regexp.MustCompile(%s)\n\n", patternVar, goSourceString(rule.Pattern))
+		checks = append(checks, v.check(fmt.Sprintf("!%s.MatchString(%s)", patternVar, v.expr), fmt.Sprintf("must match pattern %s", rule.Pattern)))
+	}
+	if rule.Enum != "" {
+		literals, err := enumLiterals(rule.Enum)
+		if err != nil {
+			err = errors.WithMessagef(err, "%s enum", v.name)
+			return nil, err
+		}
+		values, seen := make([]string, 0, len(literals)), make(map[string]bool, len(literals))
+		for _, literal := range literals {
+			value := literal
+			if err := json.Unmarshal([]byte(literal), &value); err != nil { // 非字符串按字面量比较
+				value = literal
+			}
+			if seen[value] { // 重复的case无法编译,如 enum=[1,"1"]
+				continue
+			}
+			seen[value] = true
+			values = append(values, strconv.Quote(value))
+		}
+		checks = append(checks, v.enumCheck(values))
+	}
+	return checks, nil
+}
+
+func (v validateAttr) numberChecks() (checks []string, err error) {
+	checks = make([]string, 0)
+	rule := v.rule
+	if rule.Minimum != nil {
+		op, msg := "<", ">="
+		if rule.ExclusiveMinimum {
+			op, msg = "<=", ">"
+		}
+		limit := strconv.FormatFloat(*rule.Minimum, 'f', -1, 64)
+		checks = append(checks, v.check(fmt.Sprintf("%s %s %s", v.numberExpr(*rule.Minimum), op, limit), fmt.Sprintf("must be %s %s", msg, limit)))
+	}
+	if rule.Maximum != nil {
+		op, msg := ">", "<="
+		if rule.ExclusiveMaximum {
+			op, msg = ">=", "<"
+		}
+		limit := strconv.FormatFloat(*rule.Maximum, 'f', -1, 64)
+		checks = append(checks, v.check(fmt.Sprintf("%s %s %s", v.numberExpr(*rule.Maximum), op, limit), fmt.Sprintf("must be %s %s", msg, limit)))
+	}
+	if rule.Enum != "" {
+		literals, err := enumLiterals(rule.Enum)
+		if err != nil {
+			err = errors.WithMessagef(err, "%s enum", v.name)
+			return nil, err
+		}
+		values, seen := make([]string, 0, len(literals)), make(map[string]bool, len(literals))
+		for _, literal := range literals {
+			value := literal
+			_ = json.Unmarshal([]byte(literal), &value) // 兼容 enum=["1","2"]
+			if _, err := strconv.ParseFloat(value, 64); err != nil || (v.isInteger() && strings.ContainsAny(value, ".eE")) {
+				err = errors.Errorf("%s enum value %s is not %s", v.name, literal, v.base)
+				return nil, err
+			}
+			number, _ := new(big.Rat).SetString(value)
+			key := number.RatString()
+			if seen[key] { // 数值相等的case重复无法编译,如 enum=[1,1.0]、[10,1e1]
+				continue
+			}
+			seen[key] = true
+			values = append(values, value)
+		}
+		checks = append(checks, v.enumCheck(values))
+	}
+	return checks, nil
+}
+
+func (v validateAttr) isInteger() bool {
+	return !strings.HasPrefix(v.base, "float")
+}
+
+// numberExpr 整数类型和小数边界比较时,转换为float64比较
+func (v validateAttr) numberExpr(limit float64) string {
+	if v.isInteger() && limit != math.Trunc(limit) {
+		return fmt.Sprintf("float64(%s)", v.expr)
+	}
+	return v.expr
+}
+
+func (v validateAttr) itemsChecks() (checks []string) {
+	checks = make([]string, 0)
+	if v.rule.MinItems > 0 {
+		checks = append(checks, v.check(fmt.Sprintf("len(%s) < %d", v.expr, v.rule.MinItems), fmt.Sprintf("items must be >= %d", v.rule.MinItems)))
+	}
+	if v.rule.MaxItems > 0 {
+		checks = append(checks, v.check(fmt.Sprintf("len(%s) > %d", v.expr, v.rule.MaxItems), fmt.Sprintf("items must be <= %d", v.rule.MaxItems)))
+	}
+	return checks
+}
+
+func (v validateAttr) enumCheck(values []string) string {
+	return fmt.Sprintf("switch %s {\ncase %s:\ndefault:\n%s\n}\n", v.expr, strings.Join(values, ", "), v.fail(fmt.Sprintf("must be one of %s", v.rule.Enum)))
+}
+
+// jsonName json tag 中的名称,没有时为字段名
+func (attr StructAttr) jsonName() (name string) {
+	name = strings.Split(reflect.StructTag(attr.Tag).Get("json"), ",")[0]
+	if name == "" {
+		name = attr.Name
+	}
+	return name
+}
+
+// wireName json数据中的key,由 ToSturct 生成的字段为fullname的最后一段,其它取json tag
+func (attr StructAttr) wireName() (name string) {
+	if attr.key != "" {
+		return attr.key
+	}
+	return attr.jsonName()
+}
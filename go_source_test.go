@@ -0,0 +1,273 @@
+package jsonschemaline_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestGoSource(t *testing.T) {
+	lineschemaStr := "version=http://json-schema.org/draft-07/schema#,direction=in,id=list\n" +
+		"fullname=pageSize,dst=Limit,format=int,required,title=每页数量,description=最大100\n" +
+		"fullname=keyword,dst=keyword,comment=关键字`模糊匹配`\n" +
+		"fullname=price,dst=price,type=number\n" +
+		"fullname=items[].id,dst=Fitems.#.Fid,format=int,required,description=主键\n" +
+		"fullname=items[].enabled,dst=Fitems.#.Fenabled,type=boolean,required"
+	lineschema, err := jsonschemaline.ParseJsonschemaline(lineschemaStr)
+	require.NoError(t, err)
+	structs := lineschema.ToSturct()
+	source, err := structs.GoSource("model")
+	require.NoError(t, err)
+
+	expected := "// Code generated by jsonschemaline. DO NOT EDIT.\n" +
+		"\n" +
+		"package model\n" +
+		"\n" +
+		"// ListLineschema lineschema of List\n" +
+		"const ListLineschema = \"version=http://json-schema.org/draft-07/schema#,direction=in,id=list\\nfullname=pageSize,dst=Limit,format=int,required,title=每页数量,description=最大100\\nfullname=keyword,dst=keyword,comment=关键字`模糊匹配`\\nfullname=price,dst=price,type=number\\nfullname=items[].id,dst=Fitems.#.Fid,format=int,required,description=主键\\nfullname=items[].enabled,dst=Fitems.#.Fenabled,type=boolean,required\"\n" +
+		"\n" +
+		"type List struct {\n" +
+		"\t// 每页数量\n" +
+		"\t// 最大100\n" +
+		"\tPageSize int `json:\"pageSize\"`\n" +
+		"\t// 关键字`模糊匹配`\n" +
+		"\tKeyword *string   `json:\"keyword\"`\n" +
+		"\tPrice   *float64  `json:\"price\"`\n" +
+		"\tItems   ListItems `json:\"items\"`\n" +
+		"}\n" +
+		"\n" +
+		"type ListItem struct {\n" +
+		"\t// 主键\n" +
+		"\tId      int  `json:\"id\"`\n" +
+		"\tEnabled bool `json:\"enabled\"`\n" +
+		"}\n" +
+		"\n" +
+		"type ListItems []ListItem\n"
+	assert.Equal(t, expected, string(source))
+
+	for i := 0; i < 5; i++ {
+		again, err := lineschema.ToSturct().GoSource("model")
+		require.NoError(t, err)
+		assert.Equal(t, string(source), string(again))
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model.go", source, parser.ParseComments)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("model", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+
+	_, err = structs.GoSource("invalid-name")
+	require.Error(t, err)
+}
+
+func TestGoSourceValidate(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=in,id=list\n" +
+		"fullname=pageSize,dst=Limit,format=int,required,minimum=1,maximum=100\n" +
+		"fullname=keyword,dst=keyword,minLength=2,maxLength=10,pattern=^[a-z]+$\n" +
+		"fullname=status,dst=status,enum=[\"on\",\"off\"],required\n" +
+		"fullname=price,dst=price,type=number,minimum=0.5,exclusiveMinimum\n" +
+		"fullname=items,dst=items,type=array,minItems=1,required\n" +
+		"fullname=items[].id,dst=Fitems.#.Fid,format=int,required,enum=[1,2,3]\n" +
+		"fullname=items[].name,dst=Fitems.#.Fname,required,maxLength=5")
+	require.NoError(t, err)
+
+	t.Run("tag", func(t *testing.T) {
+		structs := lineschema.ToSturctWithOptions(jsonschemaline.StructOptions{ValidateTag: true})
+		tags := make(map[string]string)
+		for _, struc := range structs {
+			for _, attr := range struc.Attrs {
+				tags[struc.Name+"."+attr.Name] = attr.Tag
+			}
+		}
+		expected := map[string]string{
+			"List.PageSize": `json:"pageSize" validate:"gte=1,lte=100"`,
+			"List.Keyword":  `json:"keyword" validate:"omitempty,min=2,max=10"`,
+			"List.Status":   `json:"status" validate:"required,oneof=on off"`,
+			"List.Price":    `json:"price" validate:"omitempty,gt=0.5"`,
+			"List.Items":    `json:"items" validate:"required,min=1,dive"`,
+			"ListItem.Id":   `json:"id" validate:"oneof=1 2 3"`,
+			"ListItem.Name": `json:"name" validate:"required,max=5"`,
+		}
+		assert.Equal(t, expected, tags)
+	})
+
+	t.Run("method", func(t *testing.T) {
+		structs := lineschema.ToSturctWithOptions(jsonschemaline.StructOptions{ValidateMethod: true})
+		source, err := structs.GoSource("model")
+		require.NoError(t, err)
+		for _, snippet := range []string{
+			"var validatePatternListKeyword = regexp.MustCompile(`^[a-z]+$`)",
+			"func (s List) Validate() error {",
+			"\tif s.Keyword != nil {\n\t\tif utf8.RuneCountInString(*s.Keyword) < 2 {\n\t\t\treturn errors.New(\"keyword: length must be >= 2\")",
+			"\tif *s.Price <= 0.5 {\n\t\t\treturn errors.New(\"price: must be > 0.5\")",
+			"\tif s.Items == nil {\n\t\treturn errors.New(\"items: required\")",
+			"\tif err := s.Items.Validate(); err != nil {\n\t\treturn fmt.Errorf(\"items.%w\", err)",
+			"func (s ListItems) Validate() error {\n\tfor i, item := range s {\n\t\tif err := item.Validate(); err != nil {\n\t\t\treturn fmt.Errorf(\"%d.%w\", i, err)",
+			"\tswitch s.Id {\n\tcase 1, 2, 3:\n",
+		} {
+			assert.Contains(t, string(source), snippet)
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "model.go", source, parser.ParseComments)
+		require.NoError(t, err)
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		_, err = conf.Check("model", fset, []*ast.File{file}, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestGoSourceValidateZeroBound(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=in,id=order\n" +
+		"fullname=price,dst=price,type=number,minimum=0,required\n" +
+		"fullname=discount,dst=discount,format=int,maximum=0")
+	require.NoError(t, err)
+
+	structs := lineschema.ToSturctWithOptions(jsonschemaline.StructOptions{ValidateTag: true})
+	root, ok := structs.GetRoot()
+	require.True(t, ok)
+	price, _ := root.GetAttr("Price")
+	assert.Equal(t, `json:"price" validate:"gte=0"`, price.Tag)
+	discount, _ := root.GetAttr("Discount")
+	assert.Equal(t, `json:"discount" validate:"omitempty,lte=0"`, discount.Tag)
+
+	source, err := lineschema.ToSturctWithOptions(jsonschemaline.StructOptions{ValidateMethod: true}).GoSource("model")
+	require.NoError(t, err)
+	for _, snippet := range []string{
+		"\tif s.Price < 0 {\n\t\treturn errors.New(\"price: must be >= 0\")",
+		"\tif s.Discount != nil {\n\t\tif *s.Discount > 0 {\n\t\t\treturn errors.New(\"discount: must be <= 0\")",
+	} {
+		assert.Contains(t, string(source), snippet)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model.go", source, parser.ParseComments)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("model", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+}
+
+func TestGoSourceValidateDuplicateEnum(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=in,id=order\n" +
+		"fullname=rate,dst=rate,type=number,enum=[1,1.0,1e0,2],required\n" +
+		"fullname=level,dst=level,format=int,enum=[1,\"1\",2],required\n" +
+		"fullname=status,dst=status,enum=[\"on\",\"on\",\"off\"],required")
+	require.NoError(t, err)
+	source, err := lineschema.ToSturctWithOptions(jsonschemaline.StructOptions{ValidateMethod: true}).GoSource("model")
+	require.NoError(t, err)
+	for _, snippet := range []string{
+		"\tswitch s.Rate {\n\tcase 1, 2:\n",
+		"\tswitch s.Level {\n\tcase 1, 2:\n",
+		"\tswitch s.Status {\n\tcase \"on\", \"off\":\n",
+	} {
+		assert.Contains(t, string(source), snippet)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model.go", source, parser.ParseComments)
+	require.NoError(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("model", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+}
+
+func TestToSturctWithOptions(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=out,id=user\n" +
+		"fullname=userId,src=userId,format=number,required\n" +
+		"fullname=avatarUrl,src=avatarUrl\n" +
+		"fullname=ids,src=ids,type=array,format=int\n" +
+		"fullname=balance,src=balance,format=decimal\n" +
+		"fullname=createdAt,src=createdAt,format=date-time,required\n" +
+		"fullname=status[].code,src=status.#.code,required\n" +
+		"fullname=data[].id,src=data.#.id,format=int,required\n" +
+		"fullname=addresses[].city,src=addresses.#.city,required")
+	require.NoError(t, err)
+	options := jsonschemaline.StructOptions{
+		TypeMap:     map[string]string{"number": "int64", "decimal": "decimal.Decimal", "date-time": "time.Time"},
+		Imports:     map[string]string{"decimal": "github.com/shopspring/decimal"},
+		Initialisms: []string{"ID", "URL"},
+		Singularize: jsonschemaline.SingularizeEnglish,
+		Pointer:     jsonschemaline.PointerOptional,
+		ArrayValue:  true,
+	}
+	source, err := lineschema.ToSturctWithOptions(options).GoSource("model")
+	require.NoError(t, err)
+	expected := "import (\n" +
+		"\t\"time\"\n" +
+		"\n" +
+		"\t\"github.com/shopspring/decimal\"\n" +
+		")\n"
+	assert.Contains(t, string(source), expected)
+	expected = "type User struct {\n" +
+		"\tUserID    int64            `json:\"userId\"`\n" +
+		"\tAvatarURL *string          `json:\"avatarUrl\"`\n" +
+		"\tIDs       []int            `json:\"ids\"`\n" +
+		"\tBalance   *decimal.Decimal `json:\"balance\"`\n" +
+		"\tCreatedAt time.Time        `json:\"createdAt\"`\n" +
+		"\tStatus    UserStatus       `json:\"status\"`\n" +
+		"\tData      UserData         `json:\"data\"`\n" +
+		"\tAddresses UserAddresses    `json:\"addresses\"`\n" +
+		"}\n"
+	assert.Contains(t, string(source), expected)
+	for _, snippet := range []string{
+		"type UserStatus []UserStatusItem\n",
+		"type UserData []UserDataItem\n",
+		"type UserAddresses []UserAddress\n",
+		"type UserDataItem struct {\n\tID int `json:\"id\"`\n}\n",
+	} {
+		assert.Contains(t, string(source), snippet)
+	}
+
+	t.Run("singularize", func(t *testing.T) {
+		options := jsonschemaline.StructOptions{
+			Singularize: func(name string) string {
+				return strings.TrimSuffix(name, "es")
+			},
+			Pointer: jsonschemaline.PointerNever,
+		}
+		structs := lineschema.ToSturctWithOptions(options)
+		_, ok := structs.Get("UserAddress")
+		assert.True(t, ok)
+		_, ok = structs.Get("UserStatusItem") // 返回值和原名相同时追加 Item
+		assert.True(t, ok)
+		root, _ := structs.GetRoot()
+		avatarURL, _ := root.GetAttr("AvatarUrl")
+		assert.Equal(t, "string", avatarURL.Type)
+	})
+
+	t.Run("zero_options", func(t *testing.T) {
+		lineschema, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=in,id=user\n" +
+			"fullname=ids,dst=ids,type=array,format=int\n" +
+			"fullname=tags[],dst=tags,format=string\n" +
+			"fullname=addresses[].city,dst=addresses.#.city,required\n" +
+			"fullname=orderList[].id,dst=orderList.#.id,format=int,required")
+		require.NoError(t, err)
+		structs := lineschema.ToSturctWithOptions(jsonschemaline.StructOptions{})
+		assert.Equal(t, lineschema.ToSturct(), structs)
+		root, _ := structs.GetRoot()
+		ids, _ := root.GetAttr("Ids")
+		assert.Equal(t, "[]*int", ids.Type)
+		tags, _ := root.GetAttr("Tags")
+		assert.Equal(t, "[]*string", tags.Type)
+		_, ok := structs.Get("UserAddresse")
+		assert.True(t, ok)
+		_, ok = structs.Get("UserOrder")
+		assert.True(t, ok)
+
+		structs = lineschema.ToSturctWithOptions(jsonschemaline.StructOptions{Singularize: jsonschemaline.SingularizeEnglish, ArrayValue: true})
+		root, _ = structs.GetRoot()
+		ids, _ = root.GetAttr("Ids")
+		assert.Equal(t, "[]int", ids.Type)
+		_, ok = structs.Get("UserAddress")
+		assert.True(t, ok)
+	})
+}
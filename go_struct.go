@@ -3,18 +3,114 @@ package jsonschemaline
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/suifengpiao14/funcs"
 )
 
+// StructOptions ToSturctWithOptions 选项,零值与 ToSturct 一致
+type StructOptions struct {
+	ValidateTag    bool                                                            // 生成 go-playground/validator 风格的 validate tag
+	ValidateMethod bool                                                            // 记录字段约束,GoSource 时为每个结构体生成 Validate() error 方法
+	TypeMap        map[string]string                                               // format 或 type 对应的go类型,format 优先,优先于注册的 Format.GoType,如 number:int64、decimal:decimal.Decimal、date-time:time.Time
+	Imports        map[string]string                                               // TypeMap 中类型的包名对应的导入路径,如 decimal:github.com/shopspring/decimal,GoSource 时使用
+	Singularize    func(name string) (singular string)                             // 数组元素的结构体名称,为空时使用内置规则(去掉 List、s 等);返回空或与name相同时追加 Item,可使用 SingularizeEnglish
+	Initialisms    []string                                                        // 命名时保持全大写的缩写,如 ID、URL
+	Pointer        func(item *JsonschemalineItem, direction string) (pointer bool) // 字段是否使用指针,数组字段为元素使用指针(如 []*T),为空时使用 PointerOptionalIn
+	ArrayValue     bool                                                            // 数组元素不使用指针,nil 切片即表示未传,如 []T
+}
+
+// PointerOptionalIn 入参非必填字段使用指针;出参、内部转换数据均已确定,使用值类型
+func PointerOptionalIn(item *JsonschemalineItem, direction string) (pointer bool) {
+	return direction == LINE_SCHEMA_DIRECTION_IN && !item.Required
+}
+
+// PointerOptional 任意方向的非必填字段均使用指针
+func PointerOptional(item *JsonschemalineItem, direction string) (pointer bool) {
+	return !item.Required
+}
+
+// PointerNever 均使用值类型
+func PointerNever(item *JsonschemalineItem, direction string) (pointer bool) {
+	return false
+}
+
+// camel 结构体、字段名称,ToCamel 后按 Initialisms 处理缩写
+func (options StructOptions) camel(name string) (camel string) {
+	camel = funcs.ToCamel(name)
+	if len(options.Initialisms) == 0 {
+		return camel
+	}
+	initialisms := make(map[string]bool, len(options.Initialisms))
+	for _, initialism := range options.Initialisms {
+		initialisms[strings.ToUpper(initialism)] = true
+	}
+	var w strings.Builder
+	runes := []rune(camel)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		word := string(runes[start:i])
+		upper := strings.ToUpper(word)
+		switch {
+		case initialisms[upper]:
+			word = upper
+		case strings.HasSuffix(word, "s") && initialisms[upper[:len(upper)-1]]: // 复数,如 Ids→IDs
+			word = upper[:len(upper)-1] + "s"
+		}
+		w.WriteString(word)
+		start = i
+	}
+	return w.String()
+}
+
+// singular 数组元素的结构体名称
+func (options StructOptions) singular(name string) (singular string) {
+	if options.Singularize == nil {
+		return complex2singularName(name)
+	}
+	singular = options.Singularize(name)
+	if singular == "" || singular == name {
+		singular = fmt.Sprintf("%sItem", name)
+	}
+	return singular
+}
+
+// goType 字段的go类型,format 为数组元素的类型
+func (options StructOptions) goType(item *JsonschemalineItem) (typ string, format string) {
+	typ, format = item.Type, item.Format
+	if f, ok := GetFormat(item.Format); ok && f.GoType != "" { // 根据格式，修改类型
+		typ, format = f.GoType, f.GoType
+	}
+	if mapped, ok := options.TypeMap[item.Format]; ok && item.Format != "" {
+		typ, format = mapped, mapped
+	} else if mapped, ok := options.TypeMap[item.Type]; ok {
+		typ = mapped
+	}
+	return typ, format
+}
+
+// pointer 字段是否使用指针
+func (options StructOptions) pointer(item *JsonschemalineItem, direction string) (pointer bool) {
+	if options.Pointer == nil {
+		return PointerOptionalIn(item, direction)
+	}
+	return options.Pointer(item, direction)
+}
+
 // jsonschemaline 生成go 结构体工具
 type Struct struct {
-	IsRoot     bool
-	Name       string
-	Lineschema string
-	Attrs      []*StructAttr
-	Type       string
+	IsRoot         bool
+	Name           string
+	Lineschema     string
+	Attrs          []*StructAttr
+	Type           string
+	ValidateMethod bool              // GoSource 时生成 Validate() error 方法
+	Imports        map[string]string `json:",omitempty"` // 类型中包名对应的导入路径,GoSource 时使用
 }
 
 // AddAttrIgnore 已经存在则跳过
@@ -53,10 +149,45 @@ func (s *Struct) GetAttr(attrName string) (structAttr *StructAttr, exists bool)
 }
 
 type StructAttr struct {
-	Name    string
-	Type    string
-	Tag     string
-	Comment string
+	Name        string
+	Type        string
+	Tag         string
+	Title       string
+	Description string
+	Comment     string
+	Rule        *StructAttrRule `json:",omitempty"` // 字段约束,StructOptions.ValidateMethod 时生成
+	key         string          // json数据中的key,即fullname的最后一段(去掉[]),如 content-type、created_at
+}
+
+// StructAttrRule 字段约束,来自 JsonschemalineItem,0、空值表示不限制;Minimum、Maximum 为nil时不限制
+type StructAttrRule struct {
+	Required         bool
+	Enum             string // json 数组
+	MinLength        int
+	MaxLength        int
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum bool
+	ExclusiveMaximum bool
+	Pattern          string
+	MinItems         int
+	MaxItems         int
+}
+
+func newStructAttrRule(item *JsonschemalineItem) (rule *StructAttrRule) {
+	return &StructAttrRule{
+		Required:         item.Required,
+		Enum:             item.Enum,
+		MinLength:        item.MinLength,
+		MaxLength:        item.MaxLength,
+		Minimum:          item.Minimum,
+		Maximum:          item.Maximum,
+		ExclusiveMinimum: item.ExclusiveMinimum,
+		ExclusiveMaximum: item.ExclusiveMaximum,
+		Pattern:          item.Pattern,
+		MinItems:         item.MinItems,
+		MaxItems:         item.MaxItems,
+	}
 }
 
 type Structs []*Struct
@@ -106,6 +237,10 @@ func (s Structs) Copy() (newStructs Structs) {
 		newStruct.Attrs = make([]*StructAttr, 0)
 		for _, attr := range struc.Attrs {
 			newAttr := *attr
+			if attr.Rule != nil {
+				rule := *attr.Rule
+				newAttr.Rule = &rule
+			}
 			newStruct.Attrs = append(newStruct.Attrs, &newAttr)
 		}
 		newStructs = append(newStructs, &newStruct)
@@ -145,3 +280,125 @@ func (s *Structs) AddNameprefix(nameprefix string) {
 		}
 	}
 }
+
+// goNumberTypes 数值类型,校验时按 minimum、maximum 处理
+var goNumberTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+// applyRule 按选项追加validate tag,withRule 时记录字段约束,dive 为元素是结构体的数组
+func (options StructOptions) applyRule(attr *StructAttr, item *JsonschemalineItem, dive bool, withRule bool) {
+	rule := newStructAttrRule(item)
+	if withRule {
+		attr.Rule = rule
+	}
+	if !options.ValidateTag {
+		return
+	}
+	if tag := structValidateTag(attr.Type, rule, dive); tag != "" {
+		attr.Tag = fmt.Sprintf(`%s validate:"%s"`, attr.Tag, tag)
+	}
+}
+
+// structValidateTag 生成 go-playground/validator 的校验规则。
+// 非指针的数值、布尔无法区分零值和未传,不生成 required;pattern 没有对应的内置规则,由 Validate 方法校验
+func structValidateTag(typ string, rule *StructAttrRule, dive bool) (tag string) {
+	isPtr := strings.HasPrefix(typ, "*")
+	base := strings.TrimPrefix(typ, "*")
+	if basic, ok := goSourceBasicTypes[base]; ok {
+		base = basic
+	}
+	isSlice := strings.HasPrefix(base, "[]") || dive
+	isString := base == "string"
+	constraints := make([]string, 0)
+	switch {
+	case isSlice:
+		if rule.MinItems > 0 {
+			constraints = append(constraints, fmt.Sprintf("min=%d", rule.MinItems))
+		}
+		if rule.MaxItems > 0 {
+			constraints = append(constraints, fmt.Sprintf("max=%d", rule.MaxItems))
+		}
+	case isString:
+		if rule.MinLength > 0 {
+			constraints = append(constraints, fmt.Sprintf("min=%d", rule.MinLength))
+		}
+		if rule.MaxLength > 0 {
+			constraints = append(constraints, fmt.Sprintf("max=%d", rule.MaxLength))
+		}
+		if oneof := validateOneof(rule.Enum); oneof != "" {
+			constraints = append(constraints, oneof)
+		}
+	case goNumberTypes[base]:
+		if rule.Minimum != nil {
+			key := "gte"
+			if rule.ExclusiveMinimum {
+				key = "gt"
+			}
+			constraints = append(constraints, fmt.Sprintf("%s=%s", key, strconv.FormatFloat(*rule.Minimum, 'f', -1, 64)))
+		}
+		if rule.Maximum != nil {
+			key := "lte"
+			if rule.ExclusiveMaximum {
+				key = "lt"
+			}
+			constraints = append(constraints, fmt.Sprintf("%s=%s", key, strconv.FormatFloat(*rule.Maximum, 'f', -1, 64)))
+		}
+		if oneof := validateOneof(rule.Enum); oneof != "" {
+			constraints = append(constraints, oneof)
+		}
+	}
+	rules := make([]string, 0)
+	if rule.Required && (isPtr || isSlice || isString || strings.HasPrefix(base, "map[")) {
+		rules = append(rules, "required")
+	}
+	if !rule.Required && len(constraints) > 0 {
+		rules = append(rules, "omitempty")
+	}
+	rules = append(rules, constraints...)
+	if dive {
+		rules = append(rules, "dive")
+	}
+	return strings.Join(rules, ",")
+}
+
+// validateOneof 枚举转换为 oneof 规则,值中含有 , | ' 时无法表达,返回空
+func validateOneof(enum string) (oneof string) {
+	if enum == "" {
+		return ""
+	}
+	values, err := enumLiterals(enum)
+	if err != nil || len(values) == 0 {
+		return ""
+	}
+	arr := make([]string, 0, len(values))
+	for _, value := range values {
+		s := value
+		if err := json.Unmarshal([]byte(value), &s); err != nil { // 非字符串保持字面量
+			s = value
+		}
+		if strings.ContainsAny(s, ",|'") {
+			return ""
+		}
+		if strings.ContainsAny(s, " \t") {
+			s = fmt.Sprintf("'%s'", s)
+		}
+		arr = append(arr, s)
+	}
+	return fmt.Sprintf("oneof=%s", strings.Join(arr, " "))
+}
+
+// enumLiterals 枚举数组中每个值的json字面量,数字保持原样
+func enumLiterals(enum string) (literals []string, err error) {
+	raws := make([]json.RawMessage, 0)
+	if err = json.Unmarshal([]byte(enum), &raws); err != nil {
+		return nil, err
+	}
+	literals = make([]string, 0, len(raws))
+	for _, raw := range raws {
+		literals = append(literals, string(raw))
+	}
+	return literals, nil
+}
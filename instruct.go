@@ -61,18 +61,15 @@ func (instructTpls InstructTpls) GetByID(id string) *InstructTpl {
 	return nil
 }
 
+// String 生成模板,输出稳定:声明语句按首次出现的顺序去重(上级在前),其后为各指令,最后为结束语句
 func (instructTpl *InstructTpl) String() string {
 	allTplArr := make([]string, 0)
 	allTplArr = append(allTplArr, fmt.Sprintf(`{{define "%s"}}`, instructTpl.ID))
-	extraStartTpls := make(map[string]bool)
+	extraStartTpls := make([]string, 0)
 	middlTpls := make([]string, 0)
-	extraEndTpls := make(map[string]bool)
+	extraEndTpls := make([]string, 0)
 	for _, instruct := range instructTpl.Instructs {
-
-		for _, extraTpl := range instruct.ExtraStartTpl {
-			extraStartTpls[extraTpl] = true
-		}
-
+		extraStartTpls = appendUnique(extraStartTpls, instruct.ExtraStartTpl...)
 		dst := instruct.Dst
 		src := instruct.Src
 		var value string
@@ -82,24 +79,28 @@ func (instructTpl *InstructTpl) String() string {
 			value = fmt.Sprintf(`{{%s . "%s" "%s"}}`, instruct.Cmd, dst, src)
 		}
 		middlTpls = append(middlTpls, value)
-		for _, extraTpl := range instruct.ExtraEndTpl {
-			extraEndTpls[extraTpl] = true
-		}
+		extraEndTpls = appendUnique(extraEndTpls, instruct.ExtraEndTpl...)
 	}
 
-	for extraTpl := range extraStartTpls {
-		allTplArr = append(allTplArr, extraTpl)
-	}
+	allTplArr = append(allTplArr, extraStartTpls...)
 	allTplArr = append(allTplArr, middlTpls...)
-	for extraTpl := range extraEndTpls {
-		allTplArr = append(allTplArr, extraTpl)
-	}
+	allTplArr = append(allTplArr, extraEndTpls...)
 
 	allTplArr = append(allTplArr, `{{end}}`)
 	out := strings.Join(allTplArr, "\n")
 	return out
 }
 
+// appendUnique 追加不存在的元素,保持原有顺序
+func appendUnique(arr []string, values ...string) []string {
+	for _, value := range values {
+		if !inArray(value, arr) {
+			arr = append(arr, value)
+		}
+	}
+	return arr
+}
+
 func ParseInstructTp(lineschema Jsonschemaline) (instructTpl *InstructTpl) {
 	instructTpl = new(InstructTpl)
 	instructTpl.ID = lineschema.Meta.ID
@@ -120,18 +121,19 @@ func ParseInstructTp(lineschema Jsonschemaline) (instructTpl *InstructTpl) {
 		}
 
 		if instruct.Tpl == "" { // 本身不是tpl的情况下，构造tpl
-			switch item.Format {
-			case "number", "int", "integer", "float":
-				instruct.Cmd = "getSetNumber"
-			default:
-				instruct.Cmd = "getSetValue"
+			instruct.Cmd = "getSetValue"
+			if f, ok := GetFormat(item.Format); ok && f.InstructCmd != "" {
+				instruct.Cmd = f.InstructCmd
 			}
 		}
 		instructTpl.Instructs = append(instructTpl.Instructs, &instruct)
 	}
 
-	if instructTpl.Type == LINE_SCHEMA_DIRECTION_OUT {
+	switch instructTpl.Type {
+	case LINE_SCHEMA_DIRECTION_OUT:
 		*instructTpl = FormatOutputTplInstruct(*instructTpl)
+	case LINE_SCHEMA_DIRECTION_CONVERT:
+		*instructTpl = FormatConvertTplInstruct(*instructTpl)
 	}
 
 	return instructTpl
@@ -146,31 +148,57 @@ func FormatOutputTplInstruct(instructTpl InstructTpl) (newInstructTpl InstructTp
 		instructs = append(instructs, &newInstruct)
 	}
 	instructs = instructs.Unique()
-	sort.Sort(instructs)
+	sort.Stable(instructs)
 	newInstructTpl.Instructs = instructs
 	return newInstructTpl
 }
 
 func FormatOutputInstruct(instruct Instruct, root string) (newInstruct Instruct) {
 	newInstruct = instruct
-	fullname := instruct.ID
-	for {
-		if fullname == "" {
-			break
+	newInstruct.ExtraStartTpl = append(newInstruct.ExtraStartTpl, parentSetValueTpls(instruct.ID, root)...)
+	return newInstruct
+}
+
+// FormatConvertTplInstruct 内部转换,目标路径由dst决定,需要提前声明dst的上级对象/数组
+func FormatConvertTplInstruct(instructTpl InstructTpl) (newInstructTpl InstructTpl) {
+	newInstructTpl = instructTpl
+	instructs := Instructs{}
+	for _, instruct := range instructTpl.Instructs {
+		newInstruct := FormatConvertInstruct(*instruct)
+		instructs = append(instructs, &newInstruct)
+	}
+	sort.Stable(instructs)
+	newInstructTpl.Instructs = instructs
+	return newInstructTpl
+}
+
+func FormatConvertInstruct(instruct Instruct) (newInstruct Instruct) {
+	newInstruct = instruct
+	if instruct.Tpl != "" { // 模板自行处理
+		return newInstruct
+	}
+	dst := strings.ReplaceAll(instruct.Dst, ".#", "[]")
+	newInstruct.ExtraStartTpl = append(newInstruct.ExtraStartTpl, parentSetValueTpls(dst, "")...)
+	return newInstruct
+}
+
+// parentSetValueTpls 生成声明上级对象、数组的模板命令,按从上级到下级排列,fullname 中数组以[]标记,root 不为空时作为前缀。
+// 只声明到第一层数组为止,数组元素及更深层的对象、数组由 getSetValue 按 .# 路径写入时生成
+func parentSetValueTpls(fullname string, root string) (tpls []string) {
+	tpls = make([]string, 0)
+	path := root
+	parts := strings.Split(fullname, ".")
+	for _, part := range parts[:len(parts)-1] {
+		name := strings.TrimSuffix(part, "[]")
+		if path != "" {
+			name = fmt.Sprintf("%s.%s", path, name)
 		}
-		lastIndex := strings.LastIndex(fullname, ".")
-		if lastIndex < 0 {
+		path = name
+		if strings.HasSuffix(part, "[]") {
+			tpls = append(tpls, fmt.Sprintf(`{{setValue . "%s" list }}`, path))
 			break
 		}
-		fullname = fullname[:lastIndex]
-		if strings.HasSuffix(fullname, "[]") {
-			fullname = strings.TrimSuffix(fullname, "[]")
-			startTpl := fmt.Sprintf(`{{setValue . "%s.%s" list }}`, root, fullname) // 数组需要后续翻转，所以先声明为对象
-			newInstruct.ExtraStartTpl = append(newInstruct.ExtraStartTpl, startTpl)
-		} else {
-			startTpl := fmt.Sprintf(`{{setValue . "%s.%s" dict }}`, root, fullname)
-			newInstruct.ExtraStartTpl = append(newInstruct.ExtraStartTpl, startTpl)
-		}
+		tpls = append(tpls, fmt.Sprintf(`{{setValue . "%s" dict }}`, path))
 	}
-	return newInstruct
+	return tpls
 }
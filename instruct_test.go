@@ -2,13 +2,17 @@ package jsonschemaline_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"text/template"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/suifengpiao14/jsonschemaline"
 )
 
 var schemalineOut = `
-version=http://json-schema.org/draft-07/schema#,id=response
+version=http://json-schema.org/draft-07/schema#,direction=out,id=response
 fullname=items[].id,src=PaginateOut.#.Fid,required
 fullname=items[].openId,src=PaginateOut.#.Fopen_id,required
 fullname=items[].type,src=PaginateOut.#.Fopen_id_type,required
@@ -19,7 +23,7 @@ fullname=pageInfo.total,src=PaginateTotalOut,required
 `
 
 var schemalineOut2 = `
-version=http://json-schema.org/draft-07/schema#,id=mainOut
+version=http://json-schema.org/draft-07/schema#,direction=out,id=mainOut
 fullname=items[].id,required,src=PaginateOut.#.Fid
 fullname=items[].identify,required,src=PaginateOut.#.Fidentify
 fullname=items[].merchantId,required,src=PaginateOut.#.Fmerchant_id
@@ -43,7 +47,7 @@ fullname=pageInfo.total,required,src=PaginateTotalOut
 //		fullname=config.status,dst=Fstatus,enum=["0","1"],format=number,required
 //	 `
 var schemalineIn2 = `
-    version=http://json-schema.org/draft-07/schema#,id=mainIn
+    version=http://json-schema.org/draft-07/schema#,direction=in,id=mainIn
 	fullname=pageSize,format=number,required,dst=Limit
 fullname=pageIndex,format=number,required,dst={{setValue . "Offset" (mul  (getValue .  "mainIn.pageIndex")   (getValue . "mainIn.pageSize"))}}
  `
@@ -66,3 +70,135 @@ func TestParseInstructIn(t *testing.T) {
 	fmt.Println(instructTp.String())
 
 }
+
+var schemalineConvert = `
+version=http://json-schema.org/draft-07/schema#,direction=convert,id=orderConvert
+fullname=id,src=order.Fid,dst=order.id,format=int
+fullname=items[].title,src=order.Fitems.#.Ftitle,dst=order.items.#.title
+`
+
+func TestParseInstructConvert(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(schemalineConvert)
+	require.NoError(t, err)
+	instructTp := jsonschemaline.ParseInstructTp(*lineschema)
+	tpl := instructTp.String()
+	assert.Contains(t, tpl, `{{getSetNumber . "order.id" "order.Fid"}}`)
+	assert.Contains(t, tpl, `{{getSetValue . "order.items.#.title" "order.Fitems.#.Ftitle"}}`)
+	assert.Contains(t, tpl, `{{setValue . "order.items" list }}`)
+	assert.Contains(t, tpl, `{{setValue . "order" dict }}`)
+}
+
+func TestExecuteIn(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(schemalineIn2)
+	require.NoError(t, err)
+	instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+	output, err := jsonschemaline.Execute(instructTpl, []byte(`{"pageSize":"20","pageIndex":"2"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Limit":20,"Offset":40}`, string(output))
+}
+
+func TestExecuteWithFuncs(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(`version=http://json-schema.org/draft-07/schema#,direction=in,id=mainIn
+fullname=name,required,dst={{setValue . "Name" (upper (getValue . "mainIn.name"))}}
+fullname=pageIndex,format=number,required,dst={{setValue . "Offset" (mul (getValue . "mainIn.pageIndex") 10)}}`)
+	require.NoError(t, err)
+	instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+	input := []byte(`{"name":"abc","pageIndex":"2"}`)
+	funcs := template.FuncMap{
+		"upper": strings.ToUpper,
+		"mul": func(a interface{}, b interface{}) int {
+			return -1
+		},
+	}
+	output, err := jsonschemaline.ExecuteWithFuncs(instructTpl, input, funcs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"ABC","Offset":-1}`, string(output))
+
+	_, err = jsonschemaline.Execute(instructTpl, input) // 追加的函数只对本次执行有效
+	require.Error(t, err)
+
+	funcMap := jsonschemaline.FuncMap()
+	delete(funcMap, "mul") // 修改返回值不影响其它调用
+	assert.Contains(t, jsonschemaline.FuncMap(), "mul")
+}
+
+func TestExecuteOut(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(schemalineOut2)
+	require.NoError(t, err)
+	instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+	input := `{
+		"PaginateOut":[
+			{"Fid":"1","Fidentify":"a","Fmerchant_id":"10","Fmerchant_name":"m","Foperate_name":"o","Fstatus":"1","Fstore_id":"20","Fstore_name":"s","Fcreate_time":"t1","Fupdate_time":"t2"},
+			{"Fid":"2","Fidentify":"b"}
+		],
+		"mainIn":{"pageIndex":"0","pageSize":"20"},
+		"PaginateTotalOut":"2"
+	}`
+	output, err := jsonschemaline.Execute(instructTpl, []byte(input))
+	require.NoError(t, err)
+	expected := `{
+		"items":[
+			{"id":"1","identify":"a","merchantId":"10","merchantName":"m","operateName":"o","status":"1","storeId":"20","storeName":"s","createTime":"t1","updateTime":"t2"},
+			{"id":"2","identify":"b"}
+		],
+		"pageInfo":{"pageIndex":"0","pageSize":"20","total":"2"}
+	}`
+	assert.JSONEq(t, expected, string(output))
+}
+
+func TestExecuteConvert(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(schemalineConvert)
+	require.NoError(t, err)
+	instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+	output, err := jsonschemaline.Execute(instructTpl, []byte(`{"order":{"Fid":"3","Fitems":[{"Ftitle":"a"},{"Ftitle":"b"}]}}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"order":{"id":3,"items":[{"title":"a"},{"title":"b"}]}}`, string(output))
+}
+
+func TestInstructTplStringStable(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=out,id=resp
+fullname=data.user.profile.name,src=Fname
+fullname=data.user.id,src=Fid
+fullname=data.items[].code,src=Fitems.#.Fcode
+fullname=total,src=Ftotal`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	expected := `{{define "resp"}}
+{{setValue . "resp.data" dict }}
+{{setValue . "resp.data.user" dict }}
+{{setValue . "resp.data.items" list }}
+{{setValue . "resp.data.user.profile" dict }}
+{{getSetValue . "total" "Ftotal"}}
+{{getSetValue . "data.user.id" "Fid"}}
+{{getSetValue . "data.items.#.code" "Fitems.#.Fcode"}}
+{{getSetValue . "data.user.profile.name" "Fname"}}
+{{end}}`
+	for i := 0; i < 20; i++ {
+		instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+		assert.Equal(t, expected, instructTpl.String())
+	}
+}
+
+func TestInstructNestedArray(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=out,id=resp
+fullname=orders[].id,src=Forders.#.Fid
+fullname=orders[].items[].skus[].id,src=Forders.#.Fitems.#.Fskus.#.Fid
+fullname=orders[].items[].skus[].tags[],src=Forders.#.Fitems.#.Fskus.#.Ftags.#
+fullname=ids[],src=Fids.#`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	instructTpl := jsonschemaline.ParseInstructTp(*lineschema)
+	expected := `{{define "resp"}}
+{{setValue . "resp.orders" list }}
+{{getSetValue . "ids.#" "Fids.#"}}
+{{getSetValue . "orders.#.id" "Forders.#.Fid"}}
+{{getSetValue . "orders.#.items.#.skus.#.id" "Forders.#.Fitems.#.Fskus.#.Fid"}}
+{{getSetValue . "orders.#.items.#.skus.#.tags.#" "Forders.#.Fitems.#.Fskus.#.Ftags.#"}}
+{{end}}`
+	assert.Equal(t, expected, instructTpl.String())
+
+	input := `{"Forders":[{"Fid":"1","Fitems":[{"Fskus":[{"Fid":"11","Ftags":["x"]},{"Fid":"12"}]}]}],"Fids":["7"]}`
+	output, err := jsonschemaline.Execute(instructTpl, []byte(input))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"orders":[{"id":"1","items":[{"skus":[{"id":"11","tags":["x"]},{"id":"12"}]}]}],"ids":["7"]}`, string(output))
+}
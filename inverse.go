@@ -0,0 +1,92 @@
+package jsonschemaline
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	NON_INVERTIBLE_REASON_TEMPLATE     = "template"     // src/dst 为模板({{...}}),属于计算字段,逆向无法还原,逆向结果中不包含该行
+	NON_INVERTIBLE_REASON_MANY_TO_ONE  = "many_to_one"  // 多行写入同一个dst,逆向时各行读取同一个值,原始数据无法区分
+	NON_INVERTIBLE_REASON_ONE_TO_MANY  = "one_to_many"  // 同一个src写入多个dst,逆向时后写入的覆盖先写入的
+	NON_INVERTIBLE_REASON_SRC_DST_NONE = "src_dst_none" // 缺少src或dst,逆向结果中不包含该行
+)
+
+// NonInvertible 无法逆向的映射
+type NonInvertible struct {
+	Fullname string `json:"fullname"`
+	Src      string `json:"src"`
+	Dst      string `json:"dst"`
+	Reason   string `json:"reason"` // 见 NON_INVERTIBLE_REASON_*
+}
+
+func (n *NonInvertible) String() string {
+	return fmt.Sprintf("fullname %s(src=%s,dst=%s): %s", n.Fullname, n.Src, n.Dst, n.Reason)
+}
+
+// NonInvertibles 逆向报告
+type NonInvertibles []*NonInvertible
+
+func (ns NonInvertibles) String() string {
+	lines := make([]string, 0, len(ns))
+	for _, n := range ns {
+		lines = append(lines, n.String())
+	}
+	return strings.Join(lines, EOF)
+}
+
+// Inverse 生成逆向的lineschema:交换src、dst,direction in↔out(convert 保持不变),fullname 不变。
+// 模板行、缺少src/dst的行不出现在结果中;多对一、一对多的行保留,但同样记录在 nonInvertibles 中
+func (l *Jsonschemaline) Inverse() (inverse *Jsonschemaline, nonInvertibles NonInvertibles) {
+	meta := *l.Meta
+	meta.LeadingComments, meta.TrailingComment = nil, ""
+	switch l.Meta.Direction {
+	case LINE_SCHEMA_DIRECTION_IN:
+		meta.Direction = LINE_SCHEMA_DIRECTION_OUT
+	case LINE_SCHEMA_DIRECTION_OUT:
+		meta.Direction = LINE_SCHEMA_DIRECTION_IN
+	}
+	inverse = &Jsonschemaline{
+		Meta:  &meta,
+		Items: make(JsonschemalineItems, 0),
+	}
+	nonInvertibles = make(NonInvertibles, 0)
+	report := func(item *JsonschemalineItem, reason string) {
+		nonInvertibles = append(nonInvertibles, &NonInvertible{Fullname: item.Fullname, Src: item.Src, Dst: item.Dst, Reason: reason})
+	}
+	srcCount, dstCount := make(map[string]int), make(map[string]int)
+	for _, item := range l.Items {
+		srcCount[item.Src]++
+		dstCount[item.Dst]++
+	}
+	for _, item := range l.Items {
+		switch {
+		case isTplValue(item.Src) || isTplValue(item.Dst):
+			report(item, NON_INVERTIBLE_REASON_TEMPLATE)
+			continue
+		case item.Src == "" || item.Dst == "":
+			report(item, NON_INVERTIBLE_REASON_SRC_DST_NONE)
+			continue
+		case dstCount[item.Dst] > 1:
+			report(item, NON_INVERTIBLE_REASON_MANY_TO_ONE)
+		case srcCount[item.Src] > 1:
+			report(item, NON_INVERTIBLE_REASON_ONE_TO_MANY)
+		}
+		inverseItem := *item
+		inverseItem.Src, inverseItem.Dst = item.Dst, item.Src
+		inverseItem.LeadingComments, inverseItem.TrailingComment = nil, ""
+		inverseItem.Lineschema = inverse
+		inverse.Items = append(inverse.Items, &inverseItem)
+	}
+	return inverse, nonInvertibles
+}
+
+// NewInverseTransformer 根据逆向的lineschema生成 Transformer,如将内部数据转换回接口格式
+func NewInverseTransformer(l *Jsonschemaline) (transformer *Transformer, nonInvertibles NonInvertibles, err error) {
+	inverse, nonInvertibles := l.Inverse()
+	transformer, err = NewTransformer(inverse)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transformer, nonInvertibles, nil
+}
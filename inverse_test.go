@@ -0,0 +1,68 @@
+package jsonschemaline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestInverse(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline(schemalineIn2)
+	require.NoError(t, err)
+	inverse, nonInvertibles := lineschema.Inverse()
+	expected := `version=http://json-schema.org/draft-07/schema#,direction=out,id=mainIn
+fullname=pageSize,src=Limit,format=number,required`
+	assert.Equal(t, expected, inverse.String())
+	require.Len(t, nonInvertibles, 1)
+	assert.Equal(t, "pageIndex", nonInvertibles[0].Fullname)
+	assert.Equal(t, jsonschemaline.NON_INVERTIBLE_REASON_TEMPLATE, nonInvertibles[0].Reason)
+
+	again, nonInvertibles := inverse.Inverse()
+	assert.Empty(t, nonInvertibles)
+	assert.Equal(t, `version=http://json-schema.org/draft-07/schema#,direction=in,id=mainIn
+fullname=pageSize,dst=Limit,format=number,required`, again.String())
+}
+
+func TestInverseReport(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=convert,id=user
+fullname=name,src=Fname,dst=name
+fullname=nickname,src=Fnickname,dst=name
+fullname=title,src=Ftitle,dst=title
+fullname=label,src=Ftitle,dst=label`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	inverse, nonInvertibles := lineschema.Inverse()
+	assert.Len(t, inverse.Items, 4)
+	reasons := make(map[string]string)
+	for _, n := range nonInvertibles {
+		reasons[n.Fullname] = n.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"name":     jsonschemaline.NON_INVERTIBLE_REASON_MANY_TO_ONE,
+		"nickname": jsonschemaline.NON_INVERTIBLE_REASON_MANY_TO_ONE,
+		"title":    jsonschemaline.NON_INVERTIBLE_REASON_ONE_TO_MANY,
+		"label":    jsonschemaline.NON_INVERTIBLE_REASON_ONE_TO_MANY,
+	}, reasons)
+}
+
+func TestInverseTransformer(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=pageSize,dst=Limit,format=int
+fullname=items[].id,dst=Fitems.#.Fid,format=int`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	transformer, err := jsonschemaline.NewTransformer(lineschema)
+	require.NoError(t, err)
+	internal, err := transformer.Transform([]byte(`{"pageSize":"20","items":[{"id":"1"},{"id":"2"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"Limit":20,"Fitems":[{"Fid":1},{"Fid":2}]}`, string(internal))
+
+	inverseTransformer, nonInvertibles, err := jsonschemaline.NewInverseTransformer(lineschema)
+	require.NoError(t, err)
+	assert.Empty(t, nonInvertibles)
+	output, err := inverseTransformer.Transform(internal)
+	require.NoError(t, err)
+	assert.Equal(t, `{"pageSize":"20","items":[{"id":"1"},{"id":"2"}]}`, string(output))
+}
@@ -14,10 +14,10 @@ type JsonSchemaFormFiledOutMore struct {
 	EnumNames        []string `json:"enumNames"`        // 枚举值标题
 	Comment          string   `json:"comment"`          // 备注
 	Const            string   `json:"const"`            // 常量
-	MultipleOf       int      `json:"multipleOf"`       // 多值
-	Maximum          int      `json:"maximum"`          // 最大值
+	MultipleOf       float64  `json:"multipleOf"`       // 多值
+	Maximum          float64  `json:"maximum"`          // 最大值
 	ExclusiveMaximum bool     `json:"exclusiveMaximum"` // 是否包含最大值
-	Minimum          int      `json:"minimum"`          // 最小值
+	Minimum          float64  `json:"minimum"`          // 最小值
 	ExclusiveMinimum bool     `json:"exclusiveMinimum"` // 是否包含最小值
 	MaxLength        int      `json:"maxLength"`        // 最大长度
 	MinLength        int      `json:"minLength"`        // 最小长度
@@ -50,10 +50,10 @@ func GetJsonSchemaFormFileds() (formFields []JsonSchemaFormFiledOut) {
 		{Fullname: "enumNames", Type: "string", Title: "枚举值标题", Description: "枚举值标题"},
 		{Fullname: "comment", Type: "string", Title: "备注", Description: "备注"},
 		{Fullname: "const", Type: "string", Title: "常量", Description: "常量"},
-		{Fullname: "multipleOf", Type: "string", Format: "int", Title: "多值", Description: "多值"},
-		{Fullname: "maximum", Type: "string", Format: "int", Title: "最大值", Description: "最大值"},
+		{Fullname: "multipleOf", Type: "string", Format: "float", Title: "多值", Description: "多值"},
+		{Fullname: "maximum", Type: "string", Format: "float", Title: "最大值", Description: "最大值"},
 		{Fullname: "exclusiveMaximum", Type: "string", Format: "bool", Title: "是否包含最大值", Description: "是否包含最大值"},
-		{Fullname: "minimum", Type: "string", Format: "int", Title: "最小值", Description: "最小值"},
+		{Fullname: "minimum", Type: "string", Format: "float", Title: "最小值", Description: "最小值"},
 		{Fullname: "exclusiveMinimum", Type: "string", Format: "bool", Title: "是否包含最小值", Description: "是否包含最小值"},
 		{Fullname: "maxLength", Type: "string", Format: "int", Title: "最大长度", Description: "最大长度"},
 		{Fullname: "minLength", Type: "string", Format: "int", Title: "最小长度", Description: "最小长度"},
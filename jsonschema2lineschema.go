@@ -6,161 +6,277 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/spf13/cast"
-	"github.com/suifengpiao14/kvstruct"
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 )
 
+// JsonSchema2LineSchema jsonschema 转 lineschema,行的顺序与jsonschema中properties、items的顺序一致。
+// 指向本文档 $defs、definitions 的 $ref 展开后转换;lineschema 无法表达的关键字(见 unsupportedJsonSchemaKeywords、jsonSchemaKeywords)返回错误
 func JsonSchema2LineSchema(jsonschema string) (lineschema *Jsonschemaline, err error) {
-	var schema map[string]interface{}
-	err = json.Unmarshal([]byte(jsonschema), &schema)
+	if !gjson.Valid(jsonschema) {
+		err = errors.Errorf("invalid json schema: %s", jsonschema)
+		return nil, err
+	}
+	schema := gjson.Parse(jsonschema)
+	version := schema.Get(`$schema`).String()
+	id := schema.Get(`$id`).String()
+	if id == "" {
+		id = "example"
+	}
+	lines := make([]string, 0)
+	lines = append(lines, fmt.Sprintf("version=%s,direction=%s,id=%s", version, LINE_SCHEMA_DIRECTION_IN, id))
+	converter := &jsonSchemaConverter{root: schema, resolving: make(map[string]bool)}
+	nodeLines, err := converter.node2Lines(schema, "", false)
 	if err != nil {
 		return nil, err
 	}
-	kvs := jsonSchema2KVS(schema, "")
-	version, _ := kvs.GetFirstByKey("$schema")
-	id, _ := kvs.GetFirstByKey("$id")
-	if id.Value == "" {
-		id.Value = "example"
+	lines = append(lines, nodeLines...)
+	lineschemastr := strings.Join(lines, EOF)
+
+	lineschema, err = ParseJsonschemaline(lineschemastr)
+	if err != nil {
+		return nil, err
 	}
-	kvs1 := dealPropertiesAndItemsdealRequired(kvs)
-	kvs2 := dealRequired(kvs1)
-	m := make(map[string][][2]string)
-	for _, kv := range kvs2 {
-		if strings.HasPrefix(kv.Key, "$") {
-			continue
+
+	return lineschema, nil
+}
+
+// unsupportedJsonSchemaKeywords lineschema 无法表达的关键字:元组(prefixItems、数组形式的items)、字段间依赖
+var unsupportedJsonSchemaKeywords = []string{"prefixItems", "dependentRequired", "dependencies", "dependentSchemas"}
+
+// jsonSchemaConverter 记录根节点用于解析 $ref,resolving 为正在展开的 $ref,避免递归引用死循环
+type jsonSchemaConverter struct {
+	root      gjson.Result
+	resolving map[string]bool
+}
+
+// node2Lines 递归将jsonschema节点转换成lineschema行,fullname为节点全称(根节点为空),required 为父节点是否要求该节点必填
+// object、array 节点除type外没有其它关键字且存在子节点时,由子节点隐含表达,不单独生成行
+func (c *jsonSchemaConverter) node2Lines(node gjson.Result, fullname string, required bool) (lines []string, err error) {
+	if ref := node.Get(`$ref`); ref.Exists() {
+		refName := ref.String()
+		if c.resolving[refName] {
+			err = errors.Errorf("json schema recursive $ref %s not supported, at fullname %q", refName, fullname)
+			return nil, err
 		}
-		lastDot := strings.LastIndex(strings.Trim(kv.Key, "."), ".")
-		fullname := ""
-		key := kv.Key
-		if lastDot > -1 {
-			fullname, key = kv.Key[:lastDot], kv.Key[lastDot+1:]
+		node, err = c.resolveRef(node, refName)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "fullname %q", fullname)
 		}
-		if _, ok := m[fullname]; !ok {
-			m[fullname] = make([][2]string, 0)
+		c.resolving[refName] = true
+		defer delete(c.resolving, refName)
+	}
+	for _, keyword := range unsupportedJsonSchemaKeywords {
+		if node.Get(keyword).Exists() {
+			err = errors.Errorf("json schema keyword %s not supported by lineschema, at fullname %q", keyword, fullname)
+			return nil, err
 		}
-
-		m[fullname] = append(m[fullname], [2]string{key, kv.Value})
 	}
-
-	var w bytes.Buffer
-	w.WriteString(fmt.Sprintf("version=%s,direction=%s,id=%s\n", version.Value, "in", id.Value))
-	for fullname, linePairs := range m {
-		if fullname == "" {
-			continue
+	properties, items := node.Get("properties"), node.Get("items")
+	if items.IsArray() {
+		err = errors.Errorf("json schema tuple items not supported by lineschema, at fullname %q", fullname)
+		return nil, err
+	}
+	lines = make([]string, 0)
+	hasChildren := len(properties.Map()) > 0 || len(items.Map()) > 0
+	keywords, err := jsonSchemaKeywords(node, fullname)
+	if err != nil {
+		return nil, err
+	}
+	if fullname != "" && (len(keywords) > 0 || required || !hasChildren) {
+		pairs := []string{
+			fmt.Sprintf("fullname=%s", quoteValue(fullname)),
+			fmt.Sprintf("dst=%s", quoteValue(defaultSrcOrDst(fullname))),
+		}
+		if typ := node.Get("type").String(); typ != "" {
+			pairs = append(pairs, fmt.Sprintf("type=%s", quoteValue(typ)))
 		}
-		pairs := make([]string, 0)
-		pairs = append(pairs, fmt.Sprintf("fullname=%s", fullname), fmt.Sprintf("dst=%s", fullname))
-		for _, pair := range linePairs {
-			pairs = append(pairs, strings.Join(pair[:], "="))
+		if required {
+			pairs = append(pairs, "required")
 		}
-		w.WriteString(strings.Join(pairs, ","))
-		w.WriteString("\n")
+		pairs = append(pairs, keywords...)
+		lines = append(lines, strings.Join(pairs, ","))
 	}
 
-	lineschemastr := w.String()
-
-	lineschema, err = ParseJsonschemaline(lineschemastr)
+	requiredNames := make(map[string]bool)
+	for _, name := range node.Get("required").Array() {
+		requiredNames[name.String()] = true
+	}
+	properties.ForEach(func(key, property gjson.Result) bool {
+		if !property.IsObject() {
+			return true
+		}
+		name := key.String()
+		childFullname := name
+		if fullname != "" {
+			childFullname = fmt.Sprintf("%s.%s", fullname, name)
+		}
+		childLines, childErr := c.node2Lines(property, childFullname, requiredNames[name])
+		if childErr != nil {
+			err = childErr
+			return false
+		}
+		lines = append(lines, childLines...)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return lineschema, nil
+	if items.IsObject() && len(items.Map()) > 0 {
+		childLines, err := c.node2Lines(items, fmt.Sprintf("%s[]", fullname), false)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, childLines...)
+	}
+	return lines, nil
 }
 
-// jsonschema 转 kvs
-func jsonSchema2KVS(schema map[string]interface{}, prefix string) kvstruct.KVS {
-	kvs := kvstruct.KVS{}
-	for key, value := range schema {
-		fieldName := fmt.Sprintf("%s%s", prefix, key)
-		switch valueType := value.(type) {
-		case map[string]interface{}:
-			// 递归处理子对象
-			kvs.Add(jsonSchema2KVS(valueType, fieldName+".")...)
-		case string:
-			kvs.Add(kvstruct.KV{
-				Key:   fieldName,
-				Value: valueType,
-			})
-		case []interface{}:
-			b, _ := json.Marshal(value)
-			valueStr := string(b)
-			kvs.Add(kvstruct.KV{
-				Key:   fieldName,
-				Value: valueStr,
-			})
-		default:
-			kvs.Add(kvstruct.KV{
-				Key:   fieldName,
-				Value: cast.ToString(value),
-			})
-		}
-	}
-	return kvs
+// resolveRef 解析本文档内的 $ref(如 #/$defs/user、#/definitions/user),与 $ref 同级的关键字覆盖引用的关键字
+func (c *jsonSchemaConverter) resolveRef(node gjson.Result, ref string) (resolved gjson.Result, err error) {
+	if !strings.HasPrefix(ref, "#/") {
+		err = errors.Errorf("json schema $ref %s not supported, only local refs like #/$defs/name", ref)
+		return resolved, err
+	}
+	paths := make([]string, 0)
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token) // json pointer 转义
+		paths = append(paths, escapeDocumentKey(token))
+	}
+	target := c.root.Get(strings.Join(paths, "."))
+	if !target.IsObject() {
+		err = errors.Errorf("json schema $ref %s not found", ref)
+		return resolved, err
+	}
+	raw := []byte(target.Raw)
+	node.ForEach(func(key, value gjson.Result) bool {
+		if key.String() == `$ref` {
+			return true
+		}
+		raw, err = sjson.SetRawBytes(raw, escapeDocumentKey(key.String()), []byte(value.Raw))
+		return err == nil
+	})
+	if err != nil {
+		return resolved, err
+	}
+	return gjson.ParseBytes(raw), nil
 }
 
-func dealPropertiesAndItemsdealRequired(kvs kvstruct.KVS) (newKvs kvstruct.KVS) {
-	newKvs = kvstruct.KVS{}
-	keywordItem := "items."
-	tmpKvs := make(kvstruct.KVS, 0)
-	for _, kv := range kvs {
-		segments := strings.Split(kv.Key, keywordItem)
-		prefix := ""
-		for i, segment := range segments {
-			parent := fmt.Sprintf("%s%s", prefix, segment)
-			parentType := fmt.Sprintf("%stype", parent)
-			parentTypeKv, _ := kvs.GetFirstByKey(parentType)
-			if parentTypeKv.Value == "array" {
-				segments[i] = "[]"
+// jsonSchemaStructureKeywords 由 node2Lines 处理的结构性关键字,根节点的 $schema、$id 作为元数据
+var jsonSchemaStructureKeywords = map[string]bool{"type": true, "properties": true, "items": true, "required": true, "$ref": true, "$defs": true, "definitions": true}
+
+// jsonSchemaKeywords 按出现顺序提取节点上lineschema支持的关键字(结构性关键字除外),返回 key=value 形式;
+// 其它关键字(如 additionalProperties、无法转换为 enum 的 oneOf、lineschema 的 fullname、src、dst 及元数据key)返回错误
+func jsonSchemaKeywords(node gjson.Result, fullname string) (pairs []string, err error) {
+	pairs = make([]string, 0)
+	itemKeywords := make(map[string]bool, len(jsonschemalineItemOrder))
+	for _, k := range jsonschemalineItemOrder {
+		itemKeywords[k] = true
+	}
+	delete(itemKeywords, "fullname")
+	delete(itemKeywords, "src")
+	delete(itemKeywords, "dst")
+	enum, enumNames := oneOf2Enum(node)
+	node.ForEach(func(key, value gjson.Result) bool {
+		k := key.String()
+		switch {
+		case jsonSchemaStructureKeywords[k]:
+			return true
+		case fullname == "" && (k == `$schema` || k == `$id`):
+			return true
+		case k == "oneOf" && enum != "":
+			return true
+		case k == `$comment`:
+			k = "comment"
+		case k == "exclusiveMaximum", k == "exclusiveMinimum":
+			pairs = append(pairs, numericExclusive2Pairs(node, k, value)...)
+			return true
+		case k == "maximum", k == "minimum":
+			if node.Get(fmt.Sprintf("exclusive%s%s", strings.ToUpper(k[:1]), k[1:])).Type == gjson.Number { // 由 numericExclusive2Pairs 合并输出
+				return true
 			}
+		case !itemKeywords[k]:
+			err = errors.Errorf("json schema keyword %s not supported by lineschema, at fullname %q", k, fullname)
+			return false
 		}
-		key := strings.Join(segments, "")
-		tmpKvs.Add(kvstruct.KV{Key: key, Value: kv.Value})
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, quoteValue(jsonSchemaValue2String(value))))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !node.Get("enum").Exists() && enum != "" {
+		pairs = append(pairs, fmt.Sprintf("enum=%s", quoteValue(enum)))
 	}
+	if !node.Get("enumNames").Exists() && enumNames != "" {
+		pairs = append(pairs, fmt.Sprintf("enumNames=%s", quoteValue(enumNames)))
+	}
+	return pairs, nil
+}
 
-	keywordProperties := "properties."
-	for _, kv := range kvs {
-		segments := strings.Split(kv.Key, keywordProperties)
-		prefix := ""
-		for i, segment := range segments {
-			parent := fmt.Sprintf("%s%s", prefix, segment)
-			parentType := fmt.Sprintf("%stype", parent)
-			parentTypeKv, _ := kvs.GetFirstByKey(parentType)
-			if parentTypeKv.Value == "object" {
-				segments[i] = ""
-			}
+// numericExclusive2Pairs exclusiveMaximum、exclusiveMinimum 转换为lineschema的布尔形式,draft-06 之后的数值形式转换为 maximum=N,exclusiveMaximum;
+// 同时存在 maximum 时取更严格的边界:exclusiveMaximum<=maximum 时输出 maximum=exclusiveMaximum,exclusiveMaximum,否则只输出 maximum
+func numericExclusive2Pairs(node gjson.Result, exclusiveKey string, value gjson.Result) (pairs []string) {
+	pairs = make([]string, 0)
+	if value.Type != gjson.Number {
+		if value.Bool() {
+			pairs = append(pairs, exclusiveKey)
 		}
-		key := strings.Join(segments, "")
-		newKvs.Add(kvstruct.KV{Key: key, Value: kv.Value})
+		return pairs
+	}
+	limitKey := "maximum"
+	if exclusiveKey == "exclusiveMinimum" {
+		limitKey = "minimum"
+	}
+	limit := node.Get(limitKey)
+	exclusiveStricter := !limit.Exists() ||
+		(limitKey == "maximum" && value.Float() <= limit.Float()) ||
+		(limitKey == "minimum" && value.Float() >= limit.Float())
+	if !exclusiveStricter {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", limitKey, limit.Raw))
+		return pairs
 	}
-	return newKvs
+	pairs = append(pairs, fmt.Sprintf("%s=%s", limitKey, value.Raw), exclusiveKey)
+	return pairs
 }
 
-func dealRequired(kvs kvstruct.KVS) (newKvs kvstruct.KVS) {
-	newKvs = make(kvstruct.KVS, 0)
-
-	for _, kv := range kvs {
-		requiredLastIndex := strings.LastIndex(kv.Key, "required")
-		if requiredLastIndex < 0 {
-			newKvs.Add(kv)
-			continue
-		}
-		prefix := kv.Key[:requiredLastIndex]
-		typeKv, _ := kvs.GetFirstByKey(fmt.Sprintf("%stype", prefix))
-		if typeKv.Value != "array" && typeKv.Value != "object" {
-			newKvs.Add(kv)
-			continue
-		}
-		var keys = make([]string, 0)
-		err := json.Unmarshal([]byte(kv.Value), &keys)
-		if err != nil {
-			panic(err)
+// oneOf2Enum oneOf:[{const,title}] 形式转换为 enum、enumNames
+func oneOf2Enum(node gjson.Result) (enum string, enumNames string) {
+	oneOf := node.Get("oneOf").Array()
+	if len(oneOf) == 0 {
+		return "", ""
+	}
+	consts, titles := make([]string, 0), make([]string, 0)
+	for _, one := range oneOf {
+		constValue := one.Get("const")
+		if !constValue.Exists() {
+			return "", ""
+		}
+		title := one.Get("title").Raw
+		if title == "" {
+			title = "null"
 		}
-		for _, k := range keys {
-			newKvs.Add(kvstruct.KV{
-				Key:   fmt.Sprintf("%s%s.required", prefix, k),
-				Value: "true",
-			})
+		consts = append(consts, constValue.Raw)
+		titles = append(titles, title)
+	}
+	enum = jsonSchemaValue2String(gjson.Parse(fmt.Sprintf("[%s]", strings.Join(consts, ","))))
+	enumNames = jsonSchemaValue2String(gjson.Parse(fmt.Sprintf("[%s]", strings.Join(titles, ","))))
+	return enum, enumNames
+}
+
+// jsonSchemaValue2String 字符串取原值,数组、对象压缩为json,其它取原始文本
+func jsonSchemaValue2String(value gjson.Result) (str string) {
+	switch {
+	case value.Type == gjson.String:
+		return value.String()
+	case value.IsArray(), value.IsObject():
+		var w bytes.Buffer
+		if err := json.Compact(&w, []byte(value.Raw)); err != nil {
+			return value.Raw
 		}
+		return w.String()
+	default:
+		return value.Raw
 	}
-	return newKvs
 }
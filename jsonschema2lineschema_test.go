@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/suifengpiao14/jsonschemaline"
 )
 
@@ -98,3 +100,93 @@ func TestParseInput(t *testing.T) {
 	// 输出键值对格式
 	fmt.Println(strings.Join(output, "\n"))
 }
+
+func TestJsonSchema2LineSchemaOrder(t *testing.T) {
+	jsonschema := `{"$schema":"http://json-schema.org/draft-07/schema#","$id":"order","type":"object","required":["zeta"],"properties":{
+		"zeta":{"type":"string","title":"Z","maxLength":10},
+		"alpha":{"type":"object","properties":{"b":{"type":"int"},"a":{"type":"int"}}},
+		"mid":{"type":"array","items":{"type":"object","properties":{"y":{"type":"string"},"x":{"type":"string","enum":["1", "2"]}}}},
+		"ids":{"type":"array","items":{"type":"int"}}
+	}}`
+	expected := `version=http://json-schema.org/draft-07/schema#,direction=in,id=order
+fullname=zeta,dst=zeta,required,title=Z,maxLength=10
+fullname=alpha.b,dst=alpha.b,type=int
+fullname=alpha.a,dst=alpha.a,type=int
+fullname=mid[].y,dst=mid.#.y
+fullname=mid[].x,dst=mid.#.x,enum=["1","2"]
+fullname=ids[],dst=ids.#,type=int`
+	for i := 0; i < 20; i++ {
+		lineschema, err := jsonschemaline.JsonSchema2LineSchema(jsonschema)
+		require.NoError(t, err)
+		assert.Equal(t, expected, lineschema.String())
+	}
+}
+
+func TestJsonSchema2LineSchemaExclusive(t *testing.T) {
+	jsonschema := `{"$schema":"http://json-schema.org/draft-07/schema#","$id":"price","type":"object","properties":{
+		"a":{"type":"number","maximum":10,"exclusiveMaximum":5},
+		"b":{"type":"number","maximum":5,"exclusiveMaximum":10},
+		"c":{"type":"number","minimum":1,"exclusiveMinimum":3},
+		"d":{"type":"number","minimum":3,"exclusiveMinimum":1},
+		"e":{"type":"number","maximum":5,"exclusiveMaximum":5},
+		"f":{"type":"number","exclusiveMinimum":0}
+	}}`
+	expected := `version=http://json-schema.org/draft-07/schema#,direction=in,id=price
+fullname=a,dst=a,type=number,maximum=5,exclusiveMaximum
+fullname=b,dst=b,type=number,maximum=5
+fullname=c,dst=c,type=number,minimum=3,exclusiveMinimum
+fullname=d,dst=d,type=number,minimum=3
+fullname=e,dst=e,type=number,maximum=5,exclusiveMaximum
+fullname=f,dst=f,type=number,minimum=0,exclusiveMinimum`
+	lineschema, err := jsonschemaline.JsonSchema2LineSchema(jsonschema)
+	require.NoError(t, err)
+	assert.Equal(t, expected, lineschema.String())
+}
+
+func TestJsonSchema2LineSchemaRef(t *testing.T) {
+	expected := `version=https://json-schema.org/draft/2020-12/schema,direction=in,id=order
+fullname=buyer.name,dst=buyer.name,required,maxLength=10
+fullname=buyer.level,dst=buyer.level,type=integer,title=等级
+fullname=items[].name,dst=items.#.name,required,maxLength=10
+fullname=items[].level,dst=items.#.level,type=integer,title=等级`
+	for _, defs := range []string{"$defs", "definitions"} {
+		jsonschema := fmt.Sprintf(`{"$schema":"https://json-schema.org/draft/2020-12/schema","$id":"order","type":"object",
+			"%[1]s":{"user":{"type":"object","required":["name"],"properties":{"name":{"type":"string","maxLength":10},"level":{"$ref":"#/%[1]s/level","title":"等级"}}},"level":{"type":"integer"}},
+			"properties":{
+				"buyer":{"$ref":"#/%[1]s/user"},
+				"items":{"type":"array","items":{"$ref":"#/%[1]s/user"}}
+			}}`, defs)
+		lineschema, err := jsonschemaline.JsonSchema2LineSchema(jsonschema)
+		require.NoError(t, err, defs)
+		assert.Equal(t, expected, lineschema.String(), defs)
+	}
+
+	errCases := map[string]string{
+		"recursive": `{"$defs":{"node":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/node"}}}}},"properties":{"root":{"$ref":"#/$defs/node"}}}`,
+		"not_found": `{"properties":{"user":{"$ref":"#/$defs/user"}}}`,
+		"remote":    `{"properties":{"user":{"$ref":"https://example.com/user.json"}}}`,
+	}
+	for name, jsonschema := range errCases {
+		_, err := jsonschemaline.JsonSchema2LineSchema(jsonschema)
+		require.Error(t, err, name)
+	}
+}
+
+func TestJsonSchema2LineSchemaUnsupported(t *testing.T) {
+	cases := map[string]string{
+		"prefixItems":          `{"properties":{"point":{"type":"array","prefixItems":[{"type":"number"},{"type":"number"}]}}}`,
+		"tuple_items":          `{"properties":{"point":{"type":"array","items":[{"type":"number"},{"type":"number"}]}}}`,
+		"dependentRequired":    `{"type":"object","properties":{"card":{"type":"string"},"address":{"type":"string"}},"dependentRequired":{"card":["address"]}}`,
+		"dependencies":         `{"type":"object","properties":{"card":{"type":"string"},"address":{"type":"string"}},"dependencies":{"card":["address"]}}`,
+		"additionalProperties": `{"type":"object","properties":{"user":{"type":"object","additionalProperties":false,"properties":{"id":{"type":"integer"}}}}}`,
+		"oneOf":                `{"type":"object","properties":{"id":{"oneOf":[{"type":"integer"},{"type":"string"}]}}}`,
+		"src":                  `{"type":"object","properties":{"id":{"type":"integer","src":"Fid"}}}`,
+		"direction":            `{"type":"object","properties":{"id":{"type":"integer","direction":"in"}}}`,
+		"nested_id":            `{"type":"object","properties":{"id":{"$id":"#id","type":"integer"}}}`,
+	}
+	for keyword, jsonschema := range cases {
+		_, err := jsonschemaline.JsonSchema2LineSchema(jsonschema)
+		require.Error(t, err, keyword)
+		assert.Contains(t, err.Error(), "not supported", keyword)
+	}
+}
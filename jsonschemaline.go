@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
@@ -20,26 +21,26 @@ import (
 type JsonschemalineItem struct {
 	Comments string `json:"comment,omitempty"` // section 8.3
 
-	Type             string `json:"type,omitempty"`                    // section 6.1.1
-	Enum             string `json:"enum,omitempty"`                    // section 6.1.2
-	EnumNames        string `json:"enumNames,omitempty"`               // section 6.1.2
-	Const            string `json:"const,omitempty"`                   // section 6.1.3
-	MultipleOf       int    `json:"multipleOf,omitempty,string"`       // section 6.2.1
-	Maximum          int    `json:"maximum,omitempty,string"`          // section 6.2.2
-	ExclusiveMaximum bool   `json:"exclusiveMaximum,omitempty,string"` // section 6.2.3
-	Minimum          int    `json:"minimum,omitempty,string"`          // section 6.2.4
-	ExclusiveMinimum bool   `json:"exclusiveMinimum,omitempty,string"` // section 6.2.5
-	MaxLength        int    `json:"maxLength,omitempty,string"`        // section 6.3.1
-	MinLength        int    `json:"minLength,omitempty,string"`        // section 6.3.2
-	Pattern          string `json:"pattern,omitempty"`                 // section 6.3.3
-	MaxItems         int    `json:"maxItems,omitempty,string"`         // section 6.4.1
-	MinItems         int    `json:"minItems,omitempty,string"`         // section 6.4.2
-	UniqueItems      bool   `json:"uniqueItems,omitempty,string"`      // section 6.4.3
-	MaxContains      uint   `json:"maxContains,omitempty,string"`      // section 6.4.4
-	MinContains      uint   `json:"minContains,omitempty,string"`      // section 6.4.5
-	MaxProperties    int    `json:"maxProperties,omitempty,string"`    // section 6.5.1
-	MinProperties    int    `json:"minProperties,omitempty,string"`    // section 6.5.2
-	Required         bool   `json:"required,omitempty,string"`         // section 6.5.3
+	Type             string   `json:"type,omitempty"`                    // section 6.1.1
+	Enum             string   `json:"enum,omitempty"`                    // section 6.1.2
+	EnumNames        string   `json:"enumNames,omitempty"`               // section 6.1.2
+	Const            string   `json:"const,omitempty"`                   // section 6.1.3
+	MultipleOf       *float64 `json:"multipleOf,omitempty,string"`       // section 6.2.1
+	Maximum          *float64 `json:"maximum,omitempty,string"`          // section 6.2.2
+	ExclusiveMaximum bool     `json:"exclusiveMaximum,omitempty,string"` // section 6.2.3
+	Minimum          *float64 `json:"minimum,omitempty,string"`          // section 6.2.4
+	ExclusiveMinimum bool     `json:"exclusiveMinimum,omitempty,string"` // section 6.2.5
+	MaxLength        int      `json:"maxLength,omitempty,string"`        // section 6.3.1
+	MinLength        int      `json:"minLength,omitempty,string"`        // section 6.3.2
+	Pattern          string   `json:"pattern,omitempty"`                 // section 6.3.3
+	MaxItems         int      `json:"maxItems,omitempty,string"`         // section 6.4.1
+	MinItems         int      `json:"minItems,omitempty,string"`         // section 6.4.2
+	UniqueItems      bool     `json:"uniqueItems,omitempty,string"`      // section 6.4.3
+	MaxContains      uint     `json:"maxContains,omitempty,string"`      // section 6.4.4
+	MinContains      uint     `json:"minContains,omitempty,string"`      // section 6.4.5
+	MaxProperties    int      `json:"maxProperties,omitempty,string"`    // section 6.5.1
+	MinProperties    int      `json:"minProperties,omitempty,string"`    // section 6.5.2
+	Required         bool     `json:"required,omitempty,string"`         // section 6.5.3
 	// RFC draft-bhutton-json-schema-validation-00, section 7
 	Format string `json:"format,omitempty"`
 	// RFC draft-bhutton-json-schema-validation-00, section 8
@@ -59,23 +60,20 @@ type JsonschemalineItem struct {
 	AllowEmptyValue  bool            `json:"allowEmptyValue,omitempty,string"`
 	TagLineKVpair    kvstruct.KVS    `json:"-"`
 	Lineschema       *Jsonschemaline `json:"-"`
+	LeadingComments  []string        `json:"-"` // 该行之前的整行注释(含注释符)
+	TrailingComment  string          `json:"-"` // 行尾注释(含注释符)
 }
 
 type JsonschemalineItems []*JsonschemalineItem
 
 func (jsonItems JsonschemalineItems) Unique() (uniqItems JsonschemalineItems) {
 	uniqItems = make(JsonschemalineItems, 0)
+	m := make(map[string]bool, len(jsonItems))
 	for _, item := range jsonItems {
-		exists := false
-		for _, uinq := range uniqItems {
-			if item.Fullname == uinq.Fullname {
-				exists = true
-				break
-			}
-		}
-		if exists {
+		if m[item.Fullname] {
 			continue
 		}
+		m[item.Fullname] = true
 		uniqItems = append(uniqItems, item)
 	}
 	return uniqItems
@@ -227,7 +225,7 @@ func enumNames2KVS(enums []interface{}, enumNames []interface{}, prefix string)
 }
 
 var jsonschemalineItemOrder = []string{
-	"fullname", "src", "dst", "type", "format", "pattern", "enum", "required", "allowEmptyValue", "title", "description", "default", "comment", "example", "deprecated", "const",
+	"fullname", "src", "dst", "type", "format", "pattern", "enum", "enumNames", "required", "allowEmptyValue", "title", "description", "default", "comment", "example", "examples", "deprecated", "const",
 	"multipleOf", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum", "maxLength", "minLength",
 	"maxItems",
 	"minItems",
@@ -243,9 +241,11 @@ var jsonschemalineItemOrder = []string{
 }
 
 type Meta struct {
-	ID        string `json:"id"`
-	Version   string `json:"version"`
-	Direction string `json:"direction"`
+	ID              string   `json:"id"`
+	Version         string   `json:"version"`
+	Direction       string   `json:"direction"`
+	LeadingComments []string `json:"-"` // 元数据行之前的整行注释(含注释符)
+	TrailingComment string   `json:"-"` // 行尾注释(含注释符)
 }
 
 func IsMetaLine(lineTags kvstruct.KVS) bool {
@@ -262,14 +262,35 @@ func IsMetaLine(lineTags kvstruct.KVS) bool {
 	return is
 }
 
+// Jsonschemaline 解析后的lineschema。
+// 解析完成后视为只读:String、JsonSchema、GjsonPath、ToSturct、Validate、Coerce 等生成方法均不修改schema,
+// 可在多个goroutine间共享并发调用;需要修改时请先解析出新的实例(如 Inverse 返回新的schema)
 type Jsonschemaline struct {
-	Meta  *Meta
-	Items JsonschemalineItems
+	Meta             *Meta
+	Items            JsonschemalineItems
+	TrailingComments []string `json:"-"` // 最后一行之后的整行注释
 }
 
+// withComments 附加注释行及行尾注释
+func withComments(lineArr []string, line string, leadingComments []string, trailingComment string) []string {
+	lineArr = append(lineArr, leadingComments...)
+	if trailingComment != "" {
+		line = fmt.Sprintf("%s %s", line, trailingComment)
+	}
+	return append(lineArr, line)
+}
+
+// String 输出规范形式(canonical form)的lineschema文本:
+//   - 首行为元数据 version,direction,id;
+//   - 每行字段按 jsonschemalineItemOrder 排序,布尔值为true时只写key,零值、false 不写;
+//   - type=string 为默认值不写;入参的src、出参的dst 与fullname推导值相同时不写;
+//   - 值需要时使用引号形式(见 quoteValue),注释原样保留。
+//
+// 规范形式重新解析后再输出,结果不变,见 RoundTripText
 func (l *Jsonschemaline) String() string {
 	lineArr := make([]string, 0)
-	lineArr = append(lineArr, fmt.Sprintf("version=%s,direction=%s,id=%s", l.Meta.Version, l.Meta.Direction, l.Meta.ID))
+	metaLine := fmt.Sprintf("version=%s,direction=%s,id=%s", l.Meta.Version, l.Meta.Direction, l.Meta.ID)
+	lineArr = withComments(lineArr, metaLine, l.Meta.LeadingComments, l.Meta.TrailingComment)
 	var linemap []map[string]string
 	b, err := json.Marshal(l.Items)
 	if err != nil {
@@ -282,16 +303,17 @@ func (l *Jsonschemaline) String() string {
 		panic(err)
 	}
 
-	for _, m := range linemap {
+	for i, m := range linemap {
 		kvArr := make([]string, 0)
 		for _, k := range jsonschemalineItemOrder {
-			if l.Meta.Direction == LINE_SCHEMA_DIRECTION_IN && k == "src" {
+			v, ok := m[k]
+			implicit := v == defaultSrcOrDst(m["fullname"]) // 和fullname推导出的值相同,解析时会自动补全
+			if l.Meta.Direction == LINE_SCHEMA_DIRECTION_IN && k == "src" && implicit {
 				continue
 			}
-			if l.Meta.Direction == LINE_SCHEMA_DIRECTION_OUT && k == "dst" {
+			if l.Meta.Direction == LINE_SCHEMA_DIRECTION_OUT && k == "dst" && implicit {
 				continue
 			}
-			v, ok := m[k]
 			if ok {
 				if k == "type" && v == "string" {
 					continue // 字符串类型,默认不写
@@ -299,13 +321,15 @@ func (l *Jsonschemaline) String() string {
 				if v == "true" {
 					kvArr = append(kvArr, k)
 				} else {
-					kvArr = append(kvArr, fmt.Sprintf("%s=%s", k, v))
+					kvArr = append(kvArr, fmt.Sprintf("%s=%s", k, quoteValue(v)))
 				}
 			}
 		}
 		line := strings.Join(kvArr, ",")
-		lineArr = append(lineArr, line)
+		item := l.Items[i]
+		lineArr = withComments(lineArr, line, item.LeadingComments, item.TrailingComment)
 	}
+	lineArr = append(lineArr, l.TrailingComments...)
 	out := strings.Join(lineArr, EOF)
 	return out
 }
@@ -319,9 +343,60 @@ func (l *Jsonschemaline) BaseNames() (names []string) {
 	return names
 }
 
+const (
+	JSON_SCHEMA_DRAFT_04      = "http://json-schema.org/draft-04/schema#"
+	JSON_SCHEMA_DRAFT_07      = "http://json-schema.org/draft-07/schema#"
+	JSON_SCHEMA_DRAFT_2019_09 = "https://json-schema.org/draft/2019-09/schema"
+	JSON_SCHEMA_DRAFT_2020_12 = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// jsonSchemaDrafts 支持输出的draft,key 为简称
+var jsonSchemaDrafts = map[string]string{
+	"draft-04": JSON_SCHEMA_DRAFT_04,
+	"draft-07": JSON_SCHEMA_DRAFT_07,
+	"2019-09":  JSON_SCHEMA_DRAFT_2019_09,
+	"2020-12":  JSON_SCHEMA_DRAFT_2020_12,
+}
+
+// jsonSchemaDraft 将简称或$schema地址转换为标准的$schema地址(忽略末尾#、http/https差异)
+func jsonSchemaDraft(draft string) (schemaURI string, ok bool) {
+	if schemaURI, ok = jsonSchemaDrafts[draft]; ok {
+		return schemaURI, true
+	}
+	normalize := func(uri string) string {
+		uri = strings.TrimSuffix(strings.TrimSpace(uri), "#")
+		return strings.TrimPrefix(strings.TrimPrefix(uri, "https://"), "http://")
+	}
+	for _, schemaURI := range jsonSchemaDrafts {
+		if normalize(schemaURI) == normalize(draft) {
+			return schemaURI, true
+		}
+	}
+	return "", false
+}
+
+// JsonSchema 生成JSON Schema,Meta.Version 为支持的draft时按该draft输出,否则按draft-07输出
 func (l *Jsonschemaline) JsonSchema() (jsonschemaByte []byte, err error) {
+	draft, ok := jsonSchemaDraft(l.Meta.Version)
+	if !ok {
+		draft = JSON_SCHEMA_DRAFT_07
+	}
+	return l.JsonSchemaWithDraft(draft)
+}
+
+// JsonSchemaWithDraft 按指定draft(draft-04、draft-07、2019-09、2020-12 或对应$schema地址)生成JSON Schema。
+// draft-04 中 exclusiveMaximum、exclusiveMinimum 为布尔值;之后的版本为数值,此时 maximum=N,exclusiveMaximum 输出为 "exclusiveMaximum":N,
+// 未设置 maximum/minimum 时不输出 exclusiveMaximum/exclusiveMinimum(各版本相同)。
+// lineschema 不生成 definitions/$defs、元组形式的items/prefixItems、dependencies/dependentRequired,这几个关键字无需按版本转换;
+// 反向转换见 JsonSchema2LineSchema
+func (l *Jsonschemaline) JsonSchemaWithDraft(draft string) (jsonschemaByte []byte, err error) {
+	schemaURI, ok := jsonSchemaDraft(draft)
+	if !ok {
+		err = errors.Errorf("unsupported json schema draft: %s", draft)
+		return nil, err
+	}
 	kvs := kvstruct.KVS{
-		{Key: "$schema", Value: "http://json-schema.org/draft-07/schema#"},
+		{Key: "$schema", Value: schemaURI},
 	}
 	for _, item := range l.Items {
 		subKvs, err := item.ToJsonSchemaKVS()
@@ -349,7 +424,9 @@ func (l *Jsonschemaline) JsonSchema() (jsonschemaByte []byte, err error) {
 		switch baseKey {
 		case "exclusiveMaximum", "exclusiveMinimum", "deprecated", "readOnly", "writeOnly", "uniqueItems":
 			value = kv.Value == "true"
-		case "multipleOf", "maximum", "minimum", "maxLength", "minLength", "maxItems", "minItems", "maxContains", "minContains", "maxProperties", "minProperties":
+		case "multipleOf", "maximum", "minimum": // 支持小数,如 multipleOf=0.01
+			value, _ = strconv.ParseFloat(kv.Value, 64)
+		case "maxLength", "minLength", "maxItems", "minItems", "maxContains", "minContains", "maxProperties", "minProperties":
 			value, _ = strconv.Atoi(kv.Value)
 		}
 		jsonschemaByte, err = sjson.SetBytes(jsonschemaByte, kv.Key, value)
@@ -357,8 +434,63 @@ func (l *Jsonschemaline) JsonSchema() (jsonschemaByte []byte, err error) {
 			return nil, err
 		}
 	}
+	jsonschemaByte, err = draftExclusive(jsonschemaByte, "", schemaURI != JSON_SCHEMA_DRAFT_04)
+	if err != nil {
+		return nil, err
+	}
 	return jsonschemaByte, nil
 }
+
+// draftExclusive 递归处理布尔形式的 exclusiveMaximum、exclusiveMinimum,path 为当前节点路径(根节点为空):
+// 没有对应的 maximum/minimum 时删除;numeric 为true时转换为 draft-06 之后的数值形式
+func draftExclusive(jsonschema []byte, path string, numeric bool) (newJsonschema []byte, err error) {
+	node := gjson.ParseBytes(jsonschema)
+	prefix := ""
+	if path != "" {
+		node = gjson.GetBytes(jsonschema, path)
+		prefix = path + "."
+	}
+	for _, keys := range [][2]string{{"exclusiveMaximum", "maximum"}, {"exclusiveMinimum", "minimum"}} {
+		exclusiveKey, limitKey := keys[0], keys[1]
+		exclusive, limit := node.Get(exclusiveKey), node.Get(limitKey)
+		if exclusive.Type != gjson.True && exclusive.Type != gjson.False {
+			continue
+		}
+		if !numeric && exclusive.Type == gjson.True && limit.Exists() {
+			continue
+		}
+		jsonschema, err = sjson.DeleteBytes(jsonschema, prefix+exclusiveKey)
+		if err != nil {
+			return nil, err
+		}
+		if exclusive.Type == gjson.False || !limit.Exists() { // 没有边界时不产生约束
+			continue
+		}
+		jsonschema, err = sjson.SetRawBytes(jsonschema, prefix+exclusiveKey, []byte(limit.Raw))
+		if err != nil {
+			return nil, err
+		}
+		jsonschema, err = sjson.DeleteBytes(jsonschema, prefix+limitKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	childPaths := make([]string, 0)
+	node.Get("properties").ForEach(func(key, value gjson.Result) bool {
+		childPaths = append(childPaths, fmt.Sprintf("%sproperties.%s", prefix, escapeDocumentKey(key.String())))
+		return true
+	})
+	if node.Get("items").IsObject() {
+		childPaths = append(childPaths, prefix+"items")
+	}
+	for _, childPath := range childPaths {
+		jsonschema, err = draftExclusive(jsonschema, childPath, numeric)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jsonschema, nil
+}
 func ReplacePathSpecalChar(path string) (newPath string) {
 	replacer := strings.NewReplacer("|", "\\|", "#", "\\#", "@", "\\@", "*", "\\*", "?", "\\?")
 	return replacer.Replace(path)
@@ -377,6 +509,8 @@ func (l *Jsonschemaline) JsonExample() (jsonExample string, err error) {
 			value = item.Example
 		} else if item.Default != "" {
 			value = item.Default
+		} else if f, ok := GetFormat(item.Format); ok && f.Example != nil && item.Type == "string" {
+			value = f.Example()
 		} else {
 			switch item.Type {
 			case "int", "integer":
@@ -436,10 +570,20 @@ func (l *Jsonschemaline) DefaultJson() (defaultJson *DefaultJson, err error) {
 }
 
 func (l *Jsonschemaline) ToSturct() (structs Structs) {
+	return l.ToSturctWithOptions(StructOptions{})
+}
+
+// ToSturctWithOptions 生成go结构体,options 控制类型映射、命名、指针规则以及是否生成校验tag、Validate方法
+func (l *Jsonschemaline) ToSturctWithOptions(options StructOptions) (structs Structs) {
+	return l.toSturct(options, options.ValidateMethod)
+}
+
+// toSturct 生成go结构体,withRule 为true时记录 StructAttr.Rule,不影响 Struct.ValidateMethod
+func (l *Jsonschemaline) toSturct(options StructOptions, withRule bool) (structs Structs) {
 	arraySuffix := "[]"
 	structs = make(Structs, 0)
 	id := string(l.Meta.ID)
-	rootStructName := funcs.ToCamel(id)
+	rootStructName := options.camel(id)
 	rootStruct := &Struct{
 		IsRoot:     true,
 		Name:       rootStructName,
@@ -447,6 +591,10 @@ func (l *Jsonschemaline) ToSturct() (structs Structs) {
 		Lineschema: l.String(),
 	}
 	structs.AddIngore(rootStruct)
+	fullnameItems := make(map[string]*JsonschemalineItem, len(l.Items))
+	for _, item := range l.Items {
+		fullnameItems[item.Fullname] = item
+	}
 	for _, item := range l.Items {
 		if item.Fullname == "" {
 			continue
@@ -455,20 +603,21 @@ func (l *Jsonschemaline) ToSturct() (structs Structs) {
 		nameArr := strings.Split(withRootFullname, ".")
 		nameCount := len(nameArr)
 		for i := 1; i < nameCount; i++ { //i从1开始,0 为root,已处理
-			parentStructName := funcs.ToCamel(strings.Join(nameArr[:i], "_"))
+			parentStructName := options.camel(strings.Join(nameArr[:i], "_"))
 			parentStruct, _ := structs.Get(parentStructName) // 一定存在
 			if strings.HasPrefix(parentStruct.Type, "[]") {
-				parentStruct, _ = structs.Get(complex2singularName(parentStructName)) //取单数, 一定存在
+				parentStruct, _ = structs.Get(options.singular(parentStructName)) //取单数, 一定存在
 			}
 			baseName := nameArr[i]
 			realBaseName := strings.TrimSuffix(baseName, arraySuffix)
 			isArray := baseName != realBaseName
-			attrName := funcs.ToCamel(realBaseName)
+			attrName := options.camel(realBaseName)
+			tag := fmt.Sprintf(`json:"%s"`, funcs.ToLowerCamel(funcs.ToCamel(realBaseName)))
 			if i < nameCount-1 { // 非最后一个,即为上级的attr,又为下级的struct
-				subStructName := funcs.ToCamel(strings.Join(nameArr[:i+1], "_"))
+				subStructName := options.camel(strings.Join(nameArr[:i+1], "_"))
 				attrType := subStructName
 				if isArray {
-					singularName := complex2singularName(attrType)
+					singularName := options.singular(attrType)
 					complexStruct := &Struct{
 						IsRoot: false,
 						Name:   attrType,
@@ -484,9 +633,13 @@ func (l *Jsonschemaline) ToSturct() (structs Structs) {
 				attr := StructAttr{
 					Name: attrName,
 					Type: attrType,
-					Tag:  fmt.Sprintf(`json:"%s"`, funcs.ToLowerCamel(attrName)),
+					Tag:  tag,
+					key:  realBaseName,
 					//Comment: comment,// 符合类型comment 无意义，不增加
 				}
+				if attrItem, ok := fullnameItems[strings.TrimSuffix(strings.Join(nameArr[1:i+1], "."), arraySuffix)]; ok { // 对象、数组本身的约束
+					options.applyRule(&attr, attrItem, isArray, withRule)
+				}
 				parentStruct.AddAttrReplace(attr)
 				subStruct := &Struct{
 					IsRoot: false,
@@ -495,66 +648,71 @@ func (l *Jsonschemaline) ToSturct() (structs Structs) {
 				structs.AddIngore(subStruct)
 				continue
 			}
-			format := item.Format
-
-			switch format { // 格式化format
-			case "number":
-				format = "int"
-			case "float":
-				format = "float64"
-			}
+			typ, format := options.goType(item)
 
 			// 最后一个
 			comment := item.Comments
 			if comment == "" {
 				comment = item.Description
 			}
-			typ := item.Type
-			// 根据格式，修改类型
-			switch format {
-			case "int":
-				typ = "int"
-			case "bool", "boolean":
-				typ = "bool"
+			isArray = isArray || strings.ToLower(item.Type) == "array" // 最后一个接受当前的type字段值
+			if isArray && typ == "array" {
+				typ = "interface{}"
+				if format != "" {
+					typ = format
+				}
 			}
-			tag := fmt.Sprintf(`json:"%s"`, funcs.ToLowerCamel(attrName))
-			if l.Meta.Direction == LINE_SCHEMA_DIRECTION_IN && !item.Required { //当作入参时,非必填字断,使用引用
+			if !(isArray && options.ArrayValue) && options.pointer(item, l.Meta.Direction) {
 				typ = fmt.Sprintf("*%s", typ)
 			}
-			isArray = isArray || strings.ToLower(item.Type) == "array" // 最后一个接受当前的type字段值
 			if isArray {
-				if typ == "array" {
-					typ = "interface{}"
-					if format != "" {
-						typ = format
-					}
-				}
 				typ = fmt.Sprintf("[]%s", typ)
 			}
 
 			newAttr := &StructAttr{
-				Name:    funcs.ToCamel(attrName),
-				Type:    typ,
-				Tag:     tag,
-				Comment: comment,
+				Name:        attrName,
+				Type:        typ,
+				Tag:         tag,
+				Title:       item.Title,
+				Description: item.Description,
+				Comment:     comment,
+				key:         realBaseName,
 			}
+			options.applyRule(newAttr, item, false, withRule)
 			attr, ok := parentStruct.GetAttr(attrName)
 			if ok { //已经存在,修正类型和备注
-				typ := newAttr.Type
-				if strings.HasPrefix(attr.Type, "[]") && !strings.HasPrefix(typ, "[]") {
-					typ = fmt.Sprintf("[]%s", typ)
+				if _, isStruct := structs.Get(attr.Type); !isStruct { // 已经由子节点生成结构体的,保持结构体类型
+					typ := newAttr.Type
+					if strings.HasPrefix(attr.Type, "[]") && !strings.HasPrefix(typ, "[]") {
+						typ = fmt.Sprintf("[]%s", typ)
+					}
+					attr.Type = typ
+					attr.Tag = newAttr.Tag
 				}
-				attr.Type = typ
 				if newAttr.Comment != "" {
 					attr.Comment = newAttr.Comment
 				}
+				if newAttr.Title != "" {
+					attr.Title = newAttr.Title
+				}
+				if newAttr.Description != "" {
+					attr.Description = newAttr.Description
+				}
+				if newAttr.Rule != nil {
+					attr.Rule = newAttr.Rule
+				}
 				continue
 			}
 			// 不存在,新增
 			parentStruct.AddAttrIgnore(*newAttr)
 		}
 	}
-
+	for _, struc := range structs {
+		struc.ValidateMethod = options.ValidateMethod
+		if len(options.Imports) > 0 {
+			struc.Imports = options.Imports
+		}
+	}
 	return structs
 }
 
@@ -575,10 +733,28 @@ func complex2singularName(name string) (friendlyName string) {
 	return friendlyName
 }
 
+// SingularizeEnglish 可用作 StructOptions.Singularize,识别常见英文复数,不是复数时返回原名(由 StructOptions 追加 Item),如 Addresses→Address、Status→StatusItem
+func SingularizeEnglish(name string) (singular string) {
+	l := len(name)
+	switch {
+	case strings.HasSuffix(name, "List") && l > 4: //优化列表命名
+		return name[:l-4]
+	case strings.HasSuffix(name, "ies") && l > 3:
+		return fmt.Sprintf("%sy", name[:l-3])
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:l-2]
+	case strings.HasSuffix(name, "ss"), strings.HasSuffix(name, "us"), strings.HasSuffix(name, "is"): // Class、Status、Analysis 不是复数
+		return name
+	case strings.HasSuffix(name, "s") && l > 1:
+		return name[:l-1]
+	}
+	return name
+}
+
 // GjsonPathWithDefaultFormat 生成格式化的jsonpath，用来重新格式化数据,比如入参字段类型全为字符串，在format中标记了实际类型，可以通过该方法获取转换数据的gjson path，从入参中提取数据后，对应字段类型就以format为准，此处仅仅提供有创意的案例，更多可以依据该思路扩展
 func (l *Jsonschemaline) GjsonPathWithDefaultFormat(ignoreID bool) (gjsonPath string) {
 	switch l.Meta.Direction {
-	case LINE_SCHEMA_DIRECTION_IN:
+	case LINE_SCHEMA_DIRECTION_IN, LINE_SCHEMA_DIRECTION_CONVERT: // 内部转换同入参,按format转换成实际类型
 		gjsonPath = l.GjsonPath(ignoreID, FormatPathFnByFormatIn)
 	case LINE_SCHEMA_DIRECTION_OUT:
 		gjsonPath = l.GjsonPath(ignoreID, FormatPathFnByFormatOut)
@@ -587,66 +763,49 @@ func (l *Jsonschemaline) GjsonPathWithDefaultFormat(ignoreID bool) (gjsonPath st
 	return gjsonPath
 }
 
+// GjsonPath 生成从源数据提取目标结构的gjson path,数组(含多层嵌套)使用 src.#.{...} 形式,如 {orders:Forders.#.{id:Fid,items:Fitems.#.{id:Fid}}}
 func (l *Jsonschemaline) GjsonPath(ignoreID bool, formatPath func(format string, src string, item *JsonschemalineItem) (path string)) (gjsonPath string) {
-	m := &map[string]interface{}{}
+	root := newGjsonPathNode()
 	for _, item := range l.Items {
+		dst, src, format := item.Dst, item.Src, item.Format
 		switch strings.ToLower(item.Type) {
 		case "array":
 			if item.Format == "" {
 				continue
 			}
-			item.Fullname = fmt.Sprintf("%s[]", item.Fullname) // 解决 ids=[1,3,4] 情况
-			item.Type = item.Format
-
+			dst, src = fmt.Sprintf("%s.#", dst), fmt.Sprintf("%s.#", src) // 解决 ids=[1,3,4] 情况
 		case "object": // 数组、对象需要遍历内部结构,忽略外部的path
 			continue
 		}
-		dst, src, format := item.Dst, item.Src, item.Format
-		dst = strings.ReplaceAll(dst, ".#", "[]") //替换成[],方便后续遍历
 		if ignoreID {
 			switch l.Meta.Direction {
 			case LINE_SCHEMA_DIRECTION_IN:
 				src = strings.TrimPrefix(src, fmt.Sprintf("%s.", l.Meta.ID))
 			case LINE_SCHEMA_DIRECTION_OUT:
 				dst = strings.TrimPrefix(dst, fmt.Sprintf("%s.", l.Meta.ID))
+			case LINE_SCHEMA_DIRECTION_CONVERT:
+				src = strings.TrimPrefix(src, fmt.Sprintf("%s.", l.Meta.ID))
+				dst = strings.TrimPrefix(dst, fmt.Sprintf("%s.", l.Meta.ID))
 			}
-
-		}
-		if formatPath != nil {
-			src = formatPath(format, src, item)
 		}
-		arr := strings.Split(dst, ".")
-		l := len(arr)
-		var ref = new(map[string]interface{})
-		*ref = *m
-		for i, key := range arr {
-			if l == i+1 {
-				(*ref)[key] = src
-			}
-			if _, ok := (*ref)[key]; !ok {
-				temp := &map[string]interface{}{}
-				(*ref)[key] = temp
-			}
-			tmp, ok := (*ref)[key].(*map[string]interface{}) //递进
-			if ok {
-				*ref = *tmp
+		itemCopy := *item // formatPath 拿到的是副本,避免回调修改schema
+		leafPath := func(src string) string {
+			if formatPath == nil {
+				return src
 			}
+			return formatPath(format, src, &itemCopy)
 		}
-
+		root.add(strings.Split(dst, ".#"), strings.Split(src, ".#"), leafPath)
 	}
-	w := recursionWrite(m)
-	gjsonPath = fmt.Sprintf("{%s}", w.String())
+	gjsonPath = root.String()
 	return gjsonPath
 }
 
-// 使用format 属性格式化转换后的路径
+// 使用format 属性格式化转换后的路径,modifier 见 RegisterFormat
 func FormatPathFnByFormatIn(format string, src string, item *JsonschemalineItem) (path string) {
 	path = src
-	switch format {
-	case "int", "float", "number":
-		path = fmt.Sprintf("%s.@tonum", src)
-	case "bool":
-		path = fmt.Sprintf("%s.@tobool", src)
+	if f, ok := GetFormat(format); ok && f.GjsonModifier != "" {
+		path = fmt.Sprintf("%s.%s", src, f.GjsonModifier)
 	}
 	return path
 }
@@ -660,40 +819,78 @@ func FormatPathFnByFormatOut(format string, src string, item *JsonschemalineItem
 	return path
 }
 
-// 生成路径
-func recursionWrite(m *map[string]interface{}) (w bytes.Buffer) {
-	writeComma := false
-	for k, v := range *m {
-		if writeComma {
-			w.WriteString(",")
+// gjsonPathNode GjsonPath 的节点,value 不为空时为叶子节点;arraySrc 不为空时为对象数组,子节点路径相对数组元素
+type gjsonPathNode struct {
+	keys     []string
+	children map[string]*gjsonPathNode
+	value    string
+	arraySrc string
+}
+
+func newGjsonPathNode() (node *gjsonPathNode) {
+	return &gjsonPathNode{keys: make([]string, 0), children: make(map[string]*gjsonPathNode)}
+}
+
+// child 按key获取子节点,不存在时按出现顺序新增
+func (node *gjsonPathNode) child(key string) (child *gjsonPathNode) {
+	child, ok := node.children[key]
+	if !ok {
+		child = newGjsonPathNode()
+		node.keys = append(node.keys, key)
+		node.children[key] = child
+	}
+	return child
+}
+
+// add 增加一条映射,dstParts、srcParts 为按 .# 切分后的路径片段,片段数不同时按不含数组处理
+func (node *gjsonPathNode) add(dstParts []string, srcParts []string, leafPath func(src string) string) {
+	if len(dstParts) != len(srcParts) {
+		dstParts, srcParts = []string{strings.Join(dstParts, "")}, []string{strings.Join(srcParts, ".#")}
+	}
+	current := node
+	for i, dstPart := range dstParts {
+		keys := strings.Split(strings.Trim(dstPart, "."), ".")
+		srcPart := strings.Trim(srcParts[i], ".")
+		if i == len(dstParts)-1 { // 叶子
+			if dstPart == "" { // 标量数组,如 ids[]
+				current.value = leafPath(fmt.Sprintf("%s.#", current.arraySrc))
+				if current.value == fmt.Sprintf("%s.#", current.arraySrc) { // 无需格式化时直接取数组
+					current.value = current.arraySrc
+				}
+				return
+			}
+			for _, key := range keys {
+				current = current.child(key)
+			}
+			current.value = leafPath(srcPart)
+			return
 		}
-		writeComma = true
-		ref, ok := v.(*map[string]interface{})
-		if !ok {
-			k = strings.TrimSuffix(k, "[]")
-			w.WriteString(fmt.Sprintf("%s:%s", k, v))
-			continue
+		for _, key := range keys {
+			current = current.child(key)
 		}
-		subw := recursionWrite(ref)
-		isArray := false
-		subwKey := subw.String()
-		var subStr string
-		if strings.HasSuffix(k, "[]") {
-			isArray = true
-			k = strings.TrimRight(k, "[]")
+		if current.arraySrc == "" {
+			current.arraySrc = srcPart
 		}
+	}
+}
 
-		if isArray {
-			subStr = fmt.Sprintf("%s:{%s}|@group", k, subwKey)
-		} else {
-			subStr = fmt.Sprintf("%s:{%s}", k, subwKey)
-		}
-		w.WriteString(subStr)
+// String 生成gjson path
+func (node *gjsonPathNode) String() string {
+	if node.value != "" {
+		return node.value
 	}
-	return w
+	pairs := make([]string, 0, len(node.keys))
+	for _, key := range node.keys {
+		pairs = append(pairs, fmt.Sprintf("%s:%s", key, node.children[key].String()))
+	}
+	obj := fmt.Sprintf("{%s}", strings.Join(pairs, ","))
+	if node.arraySrc != "" {
+		return fmt.Sprintf("%s.#.%s", node.arraySrc, obj)
+	}
+	return obj
 }
 
-// Json2lineSchema
+// Json2lineSchema 根据json案例生成lineschema,数值、布尔值按go类型取已注册的format(见 formatByGoType)
 func Json2lineSchema(jsonStr string) (out *Jsonschemaline, err error) {
 	out = &Jsonschemaline{
 		Meta: &Meta{
@@ -722,12 +919,26 @@ func parseOneJsonKey2Line(rv reflect.Value, fullname string) (items Jsonschemali
 	rv = reflect.Indirect(rv)
 	kind := rv.Kind()
 	switch kind {
-	case reflect.Int, reflect.Float64, reflect.Int64:
+	case reflect.Int, reflect.Float64, reflect.Int64, reflect.Bool:
+		goType, example := "bool", ""
+		switch kind {
+		case reflect.Bool:
+			example = strconv.FormatBool(rv.Bool())
+		case reflect.Float64:
+			goType, example = "float64", strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+			if rv.Float() == math.Trunc(rv.Float()) { // json 中的整数
+				goType = "int"
+			}
+		default:
+			goType, example = "int", strconv.FormatInt(rv.Int(), 10)
+		}
 		item := &JsonschemalineItem{
 			Type:     "string",
-			Format:   "number",
 			Fullname: fullname,
-			Example:  rv.String(),
+			Example:  example,
+		}
+		if format, ok := formatByGoType(goType); ok {
+			item.Format = format.Name
 		}
 		items = append(items, item)
 	case reflect.String:
@@ -772,10 +983,10 @@ const (
 	fullname=enum,dst=enum,type=array,format=string,title=枚举值
 	fullname=enumNames,dst=enumNames,type=array,format=string,title=枚举值标题
 	fullname=const,dst=const,title=常量
-	fullname=multipleOf,dst=multipleOf,format=int,title=多值
-	fullname=maximum,dst=maximum,format=int,title=最大值
+	fullname=multipleOf,dst=multipleOf,format=float,title=多值
+	fullname=maximum,dst=maximum,format=float,title=最大值
 	fullname=exclusiveMaximum,dst=exclusiveMaximum,format=bool,title=是否包含最大值
-	fullname=minimum,dst=minimum,format=int,title=最小值
+	fullname=minimum,dst=minimum,format=float,title=最小值
 	fullname=exclusiveMinimum,dst=exclusiveMinimum,format=bool,title=是否包含最小值
 	fullname=maxLength,dst=maxLength,format=int,title=最大长度
 	fullname=minLength,dst=minLength,format=int,title=最小长度
@@ -2,6 +2,7 @@ package jsonschemaline_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -653,3 +654,246 @@ fullname=PaginateOut[].FupdatedAt,src=PaginateOut.#.updated_at,required
 	})
 
 }
+
+func TestJsonSchemalineStringQuoted(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=name,dst=name,title="Order list",pattern="^a b$",description="a,required"`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	item := lineschema.Items[0]
+	assert.Equal(t, "Order list", item.Title)
+	assert.Equal(t, "^a b$", item.Pattern)
+	assert.Equal(t, "a,required", item.Description)
+	assert.False(t, item.Required)
+
+	again, err := jsonschemaline.ParseJsonschemaline(lineschema.String())
+	require.NoError(t, err)
+	assert.Equal(t, lineschema.String(), again.String())
+	assert.Equal(t, item.Title, again.Items[0].Title)
+	assert.Equal(t, item.Pattern, again.Items[0].Pattern)
+	assert.Equal(t, item.Description, again.Items[0].Description)
+
+	t.Run("json_string_literal", func(t *testing.T) {
+		line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=status,dst=status,enum=["a b", "c,d=e"],examples=["x  y"],example="on off",default=[ "p q" ]`
+		lineschema, err := jsonschemaline.ParseJsonschemalineWithOptions(line, jsonschemaline.ParseOptions{Strict: true})
+		require.NoError(t, err)
+		item := lineschema.Items[0]
+		assert.Equal(t, `["a b","c,d=e"]`, item.Enum)
+		assert.Equal(t, `["x  y"]`, item.Examples)
+		assert.Equal(t, "on off", item.Example)
+		assert.Equal(t, `["p q"]`, item.Default)
+
+		again, err := jsonschemaline.ParseJsonschemaline(lineschema.String())
+		require.NoError(t, err)
+		assert.Equal(t, item.Enum, again.Items[0].Enum)
+		assert.Equal(t, item.Examples, again.Items[0].Examples)
+		assert.Equal(t, item.Default, again.Items[0].Default)
+	})
+}
+
+func TestJsonSchemalineComments(t *testing.T) {
+	line := `
+# 分页查询入参
+version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+
+// 页码
+fullname=pageIndex,dst=pageIndex,format=number, # 从0开始
+   
+fullname=pageSize,dst=Limit,title="Page size", // 每页数量
+# end
+`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	require.Len(t, lineschema.Items, 2)
+	assert.Equal(t, []string{"# 分页查询入参"}, lineschema.Meta.LeadingComments)
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", lineschema.Meta.Version)
+	assert.Equal(t, []string{"// 页码"}, lineschema.Items[0].LeadingComments)
+	assert.Equal(t, "# 从0开始", lineschema.Items[0].TrailingComment)
+	assert.Equal(t, "number", lineschema.Items[0].Format)
+	assert.Equal(t, "// 每页数量", lineschema.Items[1].TrailingComment)
+	assert.Equal(t, "Page size", lineschema.Items[1].Title)
+
+	expected := `# 分页查询入参
+version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+// 页码
+fullname=pageIndex,dst=pageIndex,format=number # 从0开始
+fullname=pageSize,dst=Limit,title="Page size" // 每页数量
+# end`
+	assert.Equal(t, expected, lineschema.String())
+
+	t.Run("trailing_comment", func(t *testing.T) {
+		line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=page,dst=page,format=number # 页码
+fullname=said,dst=said,title="he said \"hi\"" # trailing
+fullname=link,dst=link,default={{.link}} // 链接`
+		lineschema, err := jsonschemaline.ParseJsonschemalineWithOptions(line, jsonschemaline.ParseOptions{Strict: true})
+		require.NoError(t, err)
+		assert.Equal(t, "number", lineschema.Items[0].Format)
+		assert.Equal(t, "# 页码", lineschema.Items[0].TrailingComment)
+		assert.Equal(t, `he said "hi"`, lineschema.Items[1].Title)
+		assert.Equal(t, "# trailing", lineschema.Items[1].TrailingComment)
+		assert.Equal(t, "{{.link}}", lineschema.Items[2].Default)
+		assert.Equal(t, "// 链接", lineschema.Items[2].TrailingComment)
+	})
+
+	t.Run("value_contains_comment_marker", func(t *testing.T) {
+		line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=link,dst=link,description=http://x//y
+fullname=code,dst=code,pattern=^a,#b,title=编码#1
+fullname=name,dst=name,title="a // b" # 名称
+fullname=mark,dst=mark,default= #`
+		lineschema, err := jsonschemaline.ParseJsonschemalineWithOptions(line, jsonschemaline.ParseOptions{Strict: true})
+		require.NoError(t, err)
+		assert.Equal(t, "http://x//y", lineschema.Items[0].Description)
+		assert.Empty(t, lineschema.Items[0].TrailingComment)
+		assert.Equal(t, "^a,#b", lineschema.Items[1].Pattern) // 逗号之后是值的延续
+		assert.Equal(t, "编码#1", lineschema.Items[1].Title)
+		assert.Empty(t, lineschema.Items[1].TrailingComment)
+		assert.Equal(t, "a // b", lineschema.Items[2].Title)
+		assert.Equal(t, "# 名称", lineschema.Items[2].TrailingComment)
+		assert.Equal(t, "#", lineschema.Items[3].Default) // 值为空时不是注释
+		assert.Empty(t, lineschema.Items[3].TrailingComment)
+
+		again, err := jsonschemaline.ParseJsonschemaline(lineschema.String())
+		require.NoError(t, err)
+		assert.Equal(t, lineschema.String(), again.String())
+	})
+}
+
+func TestDirectionConvert(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=convert,id=orderConvert
+fullname=id,src=order.Fid,dst=order.id,format=int
+fullname=valid,src=order.Fvalid,dst=order.valid,format=bool`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	assert.Equal(t, line, lineschema.String())
+
+	gjsonPath := lineschema.GjsonPathWithDefaultFormat(false)
+	out := gjson.Get(`{"order":{"Fid":"12","Fvalid":"1"}}`, gjsonPath).String()
+	assert.JSONEq(t, `{"order":{"id":12,"valid":true}}`, out)
+
+	structs := lineschema.ToSturct()
+	root, ok := structs.GetRoot()
+	require.True(t, ok)
+	attr, ok := root.GetAttr("Id")
+	require.True(t, ok)
+	assert.Equal(t, "int", attr.Type)
+}
+
+func TestJsonSchemalineFloatConstraint(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=price
+fullname=price,dst=price,format=float,multipleOf=0.01,maximum=99.5,minimum=-0.5
+fullname=count,dst=count,format=int,maximum=10,minimum=1`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	assert.Equal(t, 0.01, *lineschema.Items[0].MultipleOf)
+	assert.Equal(t, 99.5, *lineschema.Items[0].Maximum)
+	assert.Equal(t, line, lineschema.String())
+
+	jsonschema, err := lineschema.JsonSchema()
+	require.NoError(t, err)
+	expected := `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","properties":{"price":{"type":"string","multipleOf":0.01,"maximum":99.5,"minimum":-0.5,"format":"float"},"count":{"type":"string","maximum":10,"minimum":1,"format":"int"}}}`
+	assert.Equal(t, expected, string(jsonschema))
+
+	back, err := jsonschemaline.JsonSchema2LineSchema(string(jsonschema))
+	require.NoError(t, err)
+	back.Meta = lineschema.Meta
+	assert.Equal(t, line, back.String())
+}
+
+func TestJsonSchemalineZeroConstraint(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=price
+fullname=price,dst=price,type=number,required,maximum=10,minimum=0
+fullname=discount,dst=discount,type=number,maximum=0,minimum=-1
+fullname=count,dst=count,type=integer`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	require.NotNil(t, lineschema.Items[0].Minimum)
+	assert.Equal(t, 0.0, *lineschema.Items[0].Minimum)
+	assert.Equal(t, 0.0, *lineschema.Items[1].Maximum)
+	assert.Nil(t, lineschema.Items[2].Minimum)
+	assert.Nil(t, lineschema.Items[2].Maximum)
+	assert.Equal(t, line, lineschema.String())
+
+	jsonschema, err := lineschema.JsonSchema()
+	require.NoError(t, err)
+	expected := `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","required":["price"],"properties":{"price":{"type":"number","maximum":10,"minimum":0},"discount":{"type":"number","maximum":0,"minimum":-1},"count":{"type":"integer"}}}`
+	assert.Equal(t, expected, string(jsonschema))
+
+	back, err := jsonschemaline.JsonSchema2LineSchema(string(jsonschema))
+	require.NoError(t, err)
+	back.Meta = lineschema.Meta
+	assert.Equal(t, line, back.String())
+}
+
+func TestJsonSchemaWithDraft(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=price
+fullname=price,dst=price,type=number,maximum=100,exclusiveMaximum,minimum=0,exclusiveMinimum`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	cases := map[string]string{
+		"draft-04":                               `{"$schema":"http://json-schema.org/draft-04/schema#","type":"object","properties":{"price":{"type":"number","maximum":100,"exclusiveMaximum":true,"minimum":0,"exclusiveMinimum":true}}}`,
+		"draft-07":                               `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","properties":{"price":{"type":"number","exclusiveMaximum":100,"exclusiveMinimum":0}}}`,
+		"2020-12":                                `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"price":{"type":"number","exclusiveMaximum":100,"exclusiveMinimum":0}}}`,
+		jsonschemaline.JSON_SCHEMA_DRAFT_2019_09: `{"$schema":"https://json-schema.org/draft/2019-09/schema","type":"object","properties":{"price":{"type":"number","exclusiveMaximum":100,"exclusiveMinimum":0}}}`,
+	}
+	for draft, expected := range cases {
+		jsonschema, err := lineschema.JsonSchemaWithDraft(draft)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(jsonschema), draft)
+
+		back, err := jsonschemaline.JsonSchema2LineSchema(string(jsonschema))
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, *back.Items[0].Maximum, draft)
+		assert.Equal(t, 0.0, *back.Items[0].Minimum, draft)
+		assert.True(t, back.Items[0].ExclusiveMaximum, draft)
+		assert.True(t, back.Items[0].ExclusiveMinimum, draft)
+	}
+
+	jsonschema, err := lineschema.JsonSchema()
+	require.NoError(t, err)
+	assert.Equal(t, cases["draft-07"], string(jsonschema))
+
+	_, err = lineschema.JsonSchemaWithDraft("draft-03")
+	require.Error(t, err)
+
+	t.Run("exclusive_without_limit", func(t *testing.T) { // 没有 maximum/minimum 时不产生约束
+		lineschema, err := jsonschemaline.ParseJsonschemaline(`version=http://json-schema.org/draft-07/schema#,direction=in,id=price
+fullname=price,dst=price,type=number,exclusiveMaximum,exclusiveMinimum`)
+		require.NoError(t, err)
+		for _, draft := range []string{"draft-04", "draft-07", "2020-12"} {
+			jsonschema, err := lineschema.JsonSchemaWithDraft(draft)
+			require.NoError(t, err)
+			price := gjson.GetBytes(jsonschema, "properties.price")
+			assert.Equal(t, `{"type":"number"}`, price.Raw, draft)
+		}
+
+		_, err = jsonschemaline.ParseJsonschemalineWithOptions(lineschema.String(), jsonschemaline.ParseOptions{Strict: true, AllErrors: true})
+		var parseErrs jsonschemaline.ParseErrors
+		require.True(t, errors.As(err, &parseErrs))
+		require.Len(t, parseErrs, 2)
+		assert.Equal(t, "exclusiveMaximum", parseErrs[0].Key)
+		assert.Equal(t, "exclusiveMinimum", parseErrs[1].Key)
+		assert.Equal(t, 2, parseErrs[0].Line)
+	})
+}
+
+func TestGjsonPathNestedArray(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=convert,id=order
+fullname=orders[].id,src=Forders.#.Fid,dst=orders.#.id,format=int
+fullname=orders[].items[].name,src=Forders.#.Fitems.#.Fname,dst=orders.#.items.#.name
+fullname=orders[].items[].skus[].id,src=Forders.#.Fitems.#.Fskus.#.Fid,dst=orders.#.items.#.skus.#.id,format=int
+fullname=orders[].items[].skus[].tags[],src=Forders.#.Fitems.#.Fskus.#.Ftags.#,dst=orders.#.items.#.skus.#.tags.#
+fullname=ids,src=Fids,dst=ids,type=array,format=int
+fullname=total,src=Ftotal,dst=total,format=int`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	gjsonPath := lineschema.GjsonPathWithDefaultFormat(false)
+	expectedPath := `{orders:Forders.#.{id:Fid.@tonum,items:Fitems.#.{name:Fname,skus:Fskus.#.{id:Fid.@tonum,tags:Ftags}}},ids:Fids.#.@tonum,total:Ftotal.@tonum}`
+	assert.Equal(t, expectedPath, gjsonPath)
+
+	input := `{"Forders":[{"Fid":"1","Fitems":[{"Fname":"a","Fskus":[{"Fid":"11","Ftags":["x","y"]},{"Fid":"12","Ftags":[]}]}]},{"Fid":"2","Fitems":[]}],"Fids":["1","2"],"Ftotal":"2"}`
+	expected := `{"orders":[{"id":1,"items":[{"name":"a","skus":[{"id":11,"tags":["x","y"]},{"id":12,"tags":[]}]}]},{"id":2,"items":[]}],"ids":[1,2],"total":2}`
+	assert.Equal(t, expected, gjson.Get(input, gjsonPath).Raw)
+}
@@ -0,0 +1,68 @@
+package jsonschemaline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	PARSE_ERROR_CODE_META_ID_REQUIRED       = "meta_id_required"       // 元数据行缺少id
+	PARSE_ERROR_CODE_META_DIRECTION_INVALID = "meta_direction_invalid" // 元数据行direction不合法
+	PARSE_ERROR_CODE_FULLNAME_REQUIRED      = "fullname_required"      // 缺少fullname
+	PARSE_ERROR_CODE_SRC_DST_REQUIRED       = "src_dst_required"       // src、dst 均为空
+	PARSE_ERROR_CODE_INVALID_VALUE          = "invalid_value"          // 值无法转换为字段类型
+	PARSE_ERROR_CODE_UNKNOWN_KEY            = "unknown_key"            // 未知key(严格模式)
+	PARSE_ERROR_CODE_DUPLICATE_FULLNAME     = "duplicate_fullname"     // fullname重复(严格模式)
+	PARSE_ERROR_CODE_SRC_DST_CONFLICT       = "src_dst_conflict"       // 多行写入同一个dst(严格模式)
+)
+
+// ParseError lineschema 解析错误,Line、Column 对应原始文本(未压缩前)的位置,方便编辑器、CI定位
+type ParseError struct {
+	Line       int    `json:"line"`   // 行号,从1开始
+	Column     int    `json:"column"` // 出错key所在列(按字符计算),从1开始,0 表示整行
+	Key        string `json:"key"`    // 出错的key,整行错误时为空
+	Text       string `json:"text"`   // 原始行文本
+	Code       string `json:"code"`   // 错误码,见 PARSE_ERROR_CODE_*
+	Msg        string `json:"msg"`
+	Suggestion string `json:"suggestion"` // 未知key时,最相近的合法key
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d,column %d: %s(%s), got:%s", e.Line, e.Column, e.Msg, e.Code, strings.TrimSpace(e.Text))
+}
+
+// ParseErrors 一次解析收集到的全部错误
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, EOF)
+}
+
+func newParseError(code string, key string, msg string) (parseError *ParseError) {
+	return &ParseError{Code: code, Key: key, Msg: msg}
+}
+
+// newLineParseError 补充行号、列号等位置信息
+func newLineParseError(err error, lineNo int, line string, pairs []linePair) (parseError *ParseError) {
+	if !errors.As(err, &parseError) {
+		parseError = newParseError(PARSE_ERROR_CODE_INVALID_VALUE, "", err.Error())
+	}
+	parseError.Line = lineNo
+	parseError.Text = line
+	if parseError.Column > 0 {
+		return parseError
+	}
+	for _, pair := range pairs {
+		if parseError.Key != "" && pair.Key == parseError.Key {
+			parseError.Column = pair.Column
+			break
+		}
+	}
+	return parseError
+}
@@ -0,0 +1,105 @@
+package jsonschemaline_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestParseError(t *testing.T) {
+	t.Run("position", func(t *testing.T) {
+		lineschema := `
+version=http://json-schema.org/draft-07/schema#,direction=in,id=example
+# 注释行、空行均计入行号
+
+	fullname=pageIndex,dst=pageIndex,format=number
+	fullname=pageSize, dst=pageSize, maxLength=abc
+`
+		_, err := jsonschemaline.ParseJsonschemaline(lineschema)
+		require.Error(t, err)
+		var parseErr *jsonschemaline.ParseError
+		require.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, 6, parseErr.Line)
+		assert.Equal(t, 35, parseErr.Column)
+		assert.Equal(t, "maxLength", parseErr.Key)
+		assert.Equal(t, jsonschemaline.PARSE_ERROR_CODE_INVALID_VALUE, parseErr.Code)
+		assert.Equal(t, "	fullname=pageSize, dst=pageSize, maxLength=abc", parseErr.Text)
+	})
+
+	t.Run("allErrors", func(t *testing.T) {
+		lineschema := `version=http://json-schema.org/draft-07/schema#,direction=inout,id=example
+fullname=pageIndex,dst=pageIndex
+dst=pageSize
+fullname=total`
+		_, err := jsonschemaline.ParseJsonschemalineWithOptions(lineschema, jsonschemaline.ParseOptions{AllErrors: true})
+		require.Error(t, err)
+		var parseErrs jsonschemaline.ParseErrors
+		require.True(t, errors.As(err, &parseErrs))
+		require.Len(t, parseErrs, 3)
+		assert.Equal(t, 1, parseErrs[0].Line)
+		assert.Equal(t, jsonschemaline.PARSE_ERROR_CODE_META_DIRECTION_INVALID, parseErrs[0].Code)
+		assert.Equal(t, 49, parseErrs[0].Column)
+		assert.Equal(t, 3, parseErrs[1].Line)
+		assert.Equal(t, jsonschemaline.PARSE_ERROR_CODE_FULLNAME_REQUIRED, parseErrs[1].Code)
+		assert.Equal(t, jsonschemaline.PARSE_ERROR_CODE_SRC_DST_REQUIRED, parseErrs[2].Code)
+	})
+}
+
+func TestParseStrict(t *testing.T) {
+	lineschema := `version=http://json-schema.org/draft-07/schema#,direction=in,id=example
+fullname=pageIndex,dst=offset,format=number,requird
+fullname=pageSize,dst=limit,formt=number,description=每页数量,默认20
+fullname=pageSize,dst=offset,enum=1
+fullname=total,dst=total,maxLength=abc`
+	t.Run("lenient", func(t *testing.T) {
+		_, err := jsonschemaline.ParseJsonschemaline(lineschema)
+		var parseErr *jsonschemaline.ParseError
+		require.True(t, errors.As(err, &parseErr))
+		assert.Equal(t, 5, parseErr.Line)
+	})
+	t.Run("strict", func(t *testing.T) {
+		_, err := jsonschemaline.ParseJsonschemalineWithOptions(lineschema, jsonschemaline.ParseOptions{Strict: true, AllErrors: true})
+		var parseErrs jsonschemaline.ParseErrors
+		require.True(t, errors.As(err, &parseErrs))
+		codes := make([]string, 0)
+		for _, parseErr := range parseErrs {
+			codes = append(codes, parseErr.Code)
+		}
+		expected := []string{
+			jsonschemaline.PARSE_ERROR_CODE_UNKNOWN_KEY,
+			jsonschemaline.PARSE_ERROR_CODE_UNKNOWN_KEY,
+			jsonschemaline.PARSE_ERROR_CODE_INVALID_VALUE,
+			jsonschemaline.PARSE_ERROR_CODE_DUPLICATE_FULLNAME,
+			jsonschemaline.PARSE_ERROR_CODE_SRC_DST_CONFLICT,
+			jsonschemaline.PARSE_ERROR_CODE_INVALID_VALUE,
+		}
+		assert.Equal(t, expected, codes)
+		assert.Equal(t, "required", parseErrs[0].Suggestion)
+		assert.Equal(t, 45, parseErrs[0].Column)
+		assert.Equal(t, "format", parseErrs[1].Suggestion)
+		assert.Equal(t, "maxLength", parseErrs[5].Key)
+	})
+	t.Run("dst_conflict", func(t *testing.T) {
+		for _, direction := range []string{jsonschemaline.LINE_SCHEMA_DIRECTION_IN, jsonschemaline.LINE_SCHEMA_DIRECTION_OUT, jsonschemaline.LINE_SCHEMA_DIRECTION_CONVERT} {
+			lineschema := fmt.Sprintf(`version=http://json-schema.org/draft-07/schema#,direction=%s,id=example
+fullname=id,src=Fid,dst=user.id
+fullname=userId,src=FuserId,dst=user.id`, direction)
+			_, err := jsonschemaline.ParseJsonschemalineWithOptions(lineschema, jsonschemaline.ParseOptions{Strict: true})
+			var parseErr *jsonschemaline.ParseError
+			require.True(t, errors.As(err, &parseErr), direction)
+			assert.Equal(t, jsonschemaline.PARSE_ERROR_CODE_SRC_DST_CONFLICT, parseErr.Code)
+			assert.Equal(t, "dst", parseErr.Key)
+			assert.Equal(t, 3, parseErr.Line)
+
+			lineschema = fmt.Sprintf(`version=http://json-schema.org/draft-07/schema#,direction=%s,id=example
+fullname=id,src=Fid,dst=id
+fullname=userId,src=Fid,dst=userId`, direction)
+			_, err = jsonschemaline.ParseJsonschemalineWithOptions(lineschema, jsonschemaline.ParseOptions{Strict: true})
+			require.NoError(t, err, direction) // 同一src读取到多个dst是合法的
+		}
+	})
+}
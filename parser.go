@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
-	"github.com/pkg/errors"
 	"github.com/suifengpiao14/kvstruct"
 )
 
@@ -14,52 +14,132 @@ const (
 	TOKEN_BEGIN = ','
 	TOKEN_END   = '='
 	EOF         = "\n"
+	QUOTE       = '"'
+
+	COMMENT_HASH  = '#' // 注释符 #
+	COMMENT_SLASH = '/' // 注释符 //
 )
 
+// ParseOptions 解析选项
+type ParseOptions struct {
+	AllErrors bool // 收集所有错误后一次性返回(ParseErrors),默认遇到第一个错误即返回(*ParseError)
+	Strict    bool // 严格模式,未知key、重复fullname、src/dst冲突、非法枚举值均报错;默认宽松模式,未知key直接忽略
+}
+
 // ParseJsonschemaline 解析lineschema
 func ParseJsonschemaline(lineschema string) (jsonline *Jsonschemaline, err error) {
-	lineschema = compress(lineschema)
+	return ParseJsonschemalineWithOptions(lineschema, ParseOptions{})
+}
+
+// ParseJsonschemalineWithOptions 按选项解析lineschema,错误中的行号、列号均对应原始文本
+func ParseJsonschemalineWithOptions(lineschema string, options ParseOptions) (jsonline *Jsonschemaline, err error) {
 	lines := strings.Split(lineschema, EOF)
 	jsonline = &Jsonschemaline{
 		Items: make([]*JsonschemalineItem, 0),
 	}
-	for _, line := range lines {
-		kvs := parserOneLine(line)
-		if IsMetaLine(kvs) {
-			meta, err := kvs2meta(kvs)
-			if err != nil {
-				return nil, err
+	errs := make(ParseErrors, 0)
+	comments := make([]string, 0)
+	checker := newStrictChecker()
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" { // 忽略所有空行
+			continue
+		}
+		if isCommentLine(line) { // 整行注释归属下一行
+			comments = append(comments, strings.TrimSpace(line))
+			continue
+		}
+		pairs, trailingComment := scanLine(line)
+		if len(pairs) == 0 { // 仅包含空白字符
+			continue
+		}
+		if options.Strict {
+			for _, parseError := range checker.checkPairs(pairs) {
+				errs = append(errs, newLineParseError(parseError, i+1, line, pairs))
+			}
+			if len(errs) > 0 && !options.AllErrors {
+				return nil, errs[0]
 			}
-			err = validMeta(meta)
+		}
+		kvs := pairs2kvs(pairs)
+		if IsMetaLine(kvs) {
+			meta, err := parseMeta(kvs)
 			if err != nil {
-				err = errors.WithMessage(err, fmt.Sprintf(" got:%s", line))
-				return nil, err
+				errs = append(errs, newLineParseError(err, i+1, line, pairs))
+				if !options.AllErrors {
+					return nil, errs[0]
+				}
+				continue
 			}
+			meta.LeadingComments, meta.TrailingComment = comments, trailingComment
+			comments = make([]string, 0)
 			jsonline.Meta = meta
 			continue
 		}
-		item, err := kv2item(kvs)
+		item, err := parseItem(kvs)
 		if err != nil {
-			return nil, err
-		}
-		err = validItem(item)
-		if err != nil {
-			err = errors.WithMessage(err, fmt.Sprintf(" got:%s", line))
-			return nil, err
+			errs = append(errs, newLineParseError(err, i+1, line, pairs))
+			if !options.AllErrors {
+				return nil, errs[0]
+			}
+			continue
 		}
-		srcOrDst := strings.ReplaceAll(item.Fullname, "[]", ".#")
-		if item.Src == "" {
-			item.Src = srcOrDst
-		} else if item.Dst == "" {
-			item.Dst = srcOrDst
+		if options.Strict {
+			for _, parseError := range checker.checkItem(jsonline.Meta, item, i+1) {
+				errs = append(errs, newLineParseError(parseError, i+1, line, pairs))
+			}
+			if len(errs) > 0 && !options.AllErrors {
+				return nil, errs[0]
+			}
 		}
+		item.LeadingComments, item.TrailingComment = comments, trailingComment
+		comments = make([]string, 0)
 		item.Lineschema = jsonline
 		jsonline.Items = append(jsonline.Items, item)
 	}
-
+	if len(comments) > 0 {
+		jsonline.TrailingComments = comments
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	return jsonline, nil
 }
 
+// parseMeta 解析元数据行
+func parseMeta(kvs kvstruct.KVS) (meta *Meta, err error) {
+	meta, err = kvs2meta(kvs)
+	if err != nil {
+		return nil, err
+	}
+	if err = validMeta(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// parseItem 解析字段行,未填写的src或dst由fullname推导
+func parseItem(kvs kvstruct.KVS) (item *JsonschemalineItem, err error) {
+	item, err = kv2item(kvs)
+	if err != nil {
+		return nil, err
+	}
+	if err = validItem(item); err != nil {
+		return nil, err
+	}
+	srcOrDst := defaultSrcOrDst(item.Fullname)
+	if item.Src == "" {
+		item.Src = srcOrDst
+	} else if item.Dst == "" {
+		item.Dst = srcOrDst
+	}
+	return item, nil
+}
+
+// defaultSrcOrDst 未填写src或dst时,由fullname推导
+func defaultSrcOrDst(fullname string) (srcOrDst string) {
+	return strings.ReplaceAll(fullname, "[]", ".#")
+}
+
 func kvs2meta(kvs kvstruct.KVS) (meta *Meta, err error) {
 	meta = new(Meta)
 	jb, err := json.Marshal(kvs.Map())
@@ -68,20 +148,24 @@ func kvs2meta(kvs kvstruct.KVS) (meta *Meta, err error) {
 	}
 	err = json.Unmarshal(jb, meta)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(PARSE_ERROR_CODE_INVALID_VALUE, "", err.Error())
 	}
 	return meta, nil
 }
 
 func validMeta(meta *Meta) (err error) {
 	if meta.ID == "" {
-		err := errors.New("meta line required id")
+		err := newParseError(PARSE_ERROR_CODE_META_ID_REQUIRED, "", "meta line required id")
 		return err
 	}
 	switch meta.Direction {
-	case LINE_SCHEMA_DIRECTION_IN, LINE_SCHEMA_DIRECTION_OUT:
+	case LINE_SCHEMA_DIRECTION_IN, LINE_SCHEMA_DIRECTION_OUT, LINE_SCHEMA_DIRECTION_CONVERT:
 	default:
-		err := errors.Errorf("meta direction must one of  [%s,%s] ,got:%s", LINE_SCHEMA_DIRECTION_IN, LINE_SCHEMA_DIRECTION_OUT, meta.Direction)
+		key := "direction"
+		if meta.Direction == "" {
+			key = ""
+		}
+		err := newParseError(PARSE_ERROR_CODE_META_DIRECTION_INVALID, key, fmt.Sprintf("meta direction must one of  [%s,%s,%s] ,got:%s", LINE_SCHEMA_DIRECTION_IN, LINE_SCHEMA_DIRECTION_OUT, LINE_SCHEMA_DIRECTION_CONVERT, meta.Direction))
 		return err
 	}
 	return nil
@@ -89,11 +173,11 @@ func validMeta(meta *Meta) (err error) {
 
 func validItem(item *JsonschemalineItem) (err error) {
 	if item.Fullname == "" {
-		err = errors.New("fullname required ")
+		err = newParseError(PARSE_ERROR_CODE_FULLNAME_REQUIRED, "", "fullname required ")
 		return err
 	}
 	if item.Src == "" && item.Dst == "" {
-		err = errors.New("at least one of dst/src required ")
+		err = newParseError(PARSE_ERROR_CODE_SRC_DST_REQUIRED, "", "at least one of dst/src required ")
 		return err
 	}
 	return nil
@@ -111,46 +195,244 @@ func kv2item(kvs kvstruct.KVS) (item *JsonschemalineItem, err error) {
 	}
 	err = json.Unmarshal(jb, item)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(PARSE_ERROR_CODE_INVALID_VALUE, invalidItemKey(kvs), err.Error())
 	}
 	return item, nil
 }
 
-func compress(lineschema string) (compressedSchema string) {
-	lineschema = strings.TrimSpace(lineschema)
-	replacer := strings.NewReplacer(" ", "", "\t", "", "\r", "")
-	compressedSchema = replacer.Replace(lineschema)
-	return compressedSchema
+// invalidItemKey 逐个key尝试转换,找到值类型不符的key
+func invalidItemKey(kvs kvstruct.KVS) (key string) {
+	for _, kv := range kvs {
+		jb, err := json.Marshal(map[string]string{kv.Key: kv.Value})
+		if err != nil {
+			return kv.Key
+		}
+		if err = json.Unmarshal(jb, new(JsonschemalineItem)); err != nil {
+			return kv.Key
+		}
+	}
+	return ""
 }
 
 // parserOneLine 解析一行数据
 func parserOneLine(line string) (kvs kvstruct.KVS) {
-	line = compress(line)
-	if line == "" {
-		return nil
+	pairs, _ := scanLine(line)
+	return pairs2kvs(pairs)
+}
+
+// linePair 一行中的一个key=value对
+type linePair struct {
+	Key    string
+	Value  string
+	Column int           // key 在原始行中的列(按字符计算),从1开始
+	Merged []lineSegment // 不是以token开头、被合并到值中的片段
+}
+
+// lineSegment 一行中以逗号分隔的片段
+type lineSegment struct {
+	Text   string
+	Column int
+}
+
+// jsonValueKeys 值为json的key,值中的json字符串字面量(如 enum=["a b","c"])原样保留
+var jsonValueKeys = map[string]bool{"enum": true, "enumNames": true, "examples": true, "default": true, "example": true, "const": true}
+
+// scanLine 将一行拆分为key=value对,同时记录key在原始行中的位置
+// 引号外的空白字符会被忽略;值以双引号开头时(如 title="Order list")按引号值处理,保留其中的空白、逗号和等号;
+// jsonValueKeys 的值中的json字符串字面量同样保留其中的空白、逗号和等号;
+// 值以 {{ 开头时按模板处理,到 }} 为止原样保留
+// 完整的值(含闭合的引号值、模板值)及其后的逗号之后,空白字符后的 # 或 // 开始行尾注释,如 format=number # 页码,通过comment返回;
+// 不在空白字符之后的 # 、// 不作为注释,如 pattern=a#b、pattern=^a,#b
+func scanLine(line string) (pairs []linePair, comment string) {
+	segments := make([]lineSegment, 0)
+	var w strings.Builder
+	column, start := 0, 0
+	inQuote, inTemplate, escaped := false, false, false
+	jsonValue := false // 当前值(含被合并的片段)是否为json
+	spaced := false    // 上一个字符是否为引号外的空白
+	runes := []rune(line)
+	for i, r := range runes {
+		column++
+		if inTemplate { // 模板值原样保留(含空白、逗号),直到 }}
+			w.WriteRune(r)
+			if r == '}' && runes[i-1] == '}' {
+				inTemplate = false
+			}
+			continue
+		}
+		if inQuote {
+			w.WriteRune(r)
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == QUOTE:
+				inQuote = false
+			}
+			continue
+		}
+		lineStart, current := w.Len() == 0 && len(segments) == 0, w.String()
+		if current == "" && len(segments) > 0 { // 逗号之后,如 format=number, # 页码
+			current = segments[len(segments)-1].Text
+		}
+		if (lineStart || (spaced && isValueEnd(current))) && isCommentStart(runes[i:]) {
+			comment = strings.TrimSpace(string(runes[i:]))
+			break
+		}
+		spaced = false
+		switch r {
+		case ' ', '\t', '\r':
+			spaced = true
+			continue
+		case TOKEN_BEGIN:
+			segments = append(segments, lineSegment{Text: w.String(), Column: start})
+			w.Reset()
+			start = 0
+			continue
+		case TOKEN_END:
+			if text := w.String(); !strings.ContainsRune(text, TOKEN_END) && isTokenKey(text) {
+				jsonValue = jsonValueKeys[text]
+			}
+		case QUOTE:
+			inQuote = isValueStart(w.String()) || jsonValue // 值的开头,或json值中的字符串字面量
+		case '{':
+			inTemplate = isValueStart(w.String()) && i+1 < len(runes) && runes[i+1] == '{'
+		}
+		if start == 0 {
+			start = column
+		}
+		w.WriteRune(r)
+	}
+	if w.Len() > 0 || comment == "" {
+		segments = append(segments, lineSegment{Text: w.String(), Column: start})
+	}
+	if len(segments) == 0 || (len(segments) == 1 && segments[0].Text == "") {
+		return nil, comment
 	}
-	ret := make([]string, 0)
-	separated := strings.Split(line, ",")
-	ret = append(ret, separated[0])
-	i := 0
-	for _, nextTag := range separated[1:] {
-		if isToken(nextTag) {
-			ret = append(ret, nextTag)
-			i++
-		} else {
-			ret[i] = fmt.Sprintf("%s,%s", ret[i], nextTag)
+
+	merged := make([]lineSegment, 0, len(segments))
+	mergedSegments := make([][]lineSegment, 0, len(segments))
+	for i, seg := range segments {
+		if i > 0 && !isToken(seg.Text) {
+			last := len(merged) - 1
+			merged[last].Text = fmt.Sprintf("%s,%s", merged[last].Text, seg.Text)
+			mergedSegments[last] = append(mergedSegments[last], seg)
+			continue
 		}
+		merged = append(merged, seg)
+		mergedSegments = append(mergedSegments, nil)
 	}
-	kvs = make(kvstruct.KVS, 0)
-	for _, pair := range ret {
-		arr := strings.SplitN(pair, "=", 2)
+	pairs = make([]linePair, 0, len(merged))
+	for i, seg := range merged {
+		arr := strings.SplitN(seg.Text, string(TOKEN_END), 2)
 		if len(arr) == 1 {
 			arr = append(arr, "true")
 		}
-		k, v := arr[0], arr[1]
+		value := arr[1]
+		if unquoted, ok := unquoteValue(value); ok {
+			value = unquoted
+		}
+		pairs = append(pairs, linePair{Key: arr[0], Value: value, Column: seg.Column, Merged: mergedSegments[i]})
+	}
+	return pairs, comment
+}
+
+// isValueStart 当前片段是否刚好写完 key=
+func isValueStart(text string) (yes bool) {
+	return strings.HasSuffix(text, string(TOKEN_END)) && strings.Count(text, string(TOKEN_END)) == 1
+}
+
+// isValueEnd 当前片段是否已经是完整的值,如 format=number、required,之后可以开始注释
+func isValueEnd(text string) (yes bool) {
+	return text != "" && !strings.HasSuffix(text, string(TOKEN_END))
+}
+
+// isCommentStart 是否以注释符(# 或 //)开头
+func isCommentStart(runes []rune) (yes bool) {
+	if len(runes) == 0 {
+		return false
+	}
+	if runes[0] == COMMENT_HASH {
+		return true
+	}
+	return len(runes) > 1 && runes[0] == COMMENT_SLASH && runes[1] == COMMENT_SLASH
+}
+
+// isCommentLine 整行注释
+func isCommentLine(line string) (yes bool) {
+	return isCommentStart([]rune(strings.TrimSpace(line)))
+}
+
+var quoteReplacer = strings.NewReplacer("\\", "\\\\", `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// quoteValue 值中包含空白字符、以引号开头或包含会被误认为新key的逗号时,输出引号形式,保证重新解析后值不变
+func quoteValue(value string) (out string) {
+	if !needQuote(value) {
+		return value
+	}
+	return fmt.Sprintf(`"%s"`, quoteReplacer.Replace(value))
+}
+
+func needQuote(value string) (yes bool) {
+	if isTplValue(value) && strings.Index(value, "}}") == len(value)-2 && !strings.ContainsAny(value, "\r\n") { // 模板值解析时原样保留
+		return false
+	}
+	if strings.HasPrefix(value, string(QUOTE)) {
+		return true
+	}
+	if strings.ContainsAny(value, " \t\r\n") {
+		return true
+	}
+	for _, seg := range strings.Split(value, string(TOKEN_BEGIN))[1:] {
+		if isToken(seg) {
+			return true
+		}
+	}
+	return false
+}
+
+// unquoteValue 解析引号值,支持 \" \\ \n \t \r 转义,其它反斜杠原样保留(方便书写正则)
+func unquoteValue(value string) (out string, ok bool) {
+	l := len(value)
+	if l < 2 || value[0] != QUOTE || value[l-1] != QUOTE {
+		return value, false
+	}
+	var w strings.Builder
+	value = value[1 : l-1]
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' || i == len(value)-1 {
+			w.WriteByte(c)
+			continue
+		}
+		i++
+		switch value[i] {
+		case QUOTE, '\\':
+			w.WriteByte(value[i])
+		case 'n':
+			w.WriteByte('\n')
+		case 't':
+			w.WriteByte('\t')
+		case 'r':
+			w.WriteByte('\r')
+		default:
+			w.WriteByte('\\')
+			w.WriteByte(value[i])
+		}
+	}
+	return w.String(), true
+}
+
+func pairs2kvs(pairs []linePair) (kvs kvstruct.KVS) {
+	if len(pairs) == 0 {
+		return nil
+	}
+	kvs = make(kvstruct.KVS, 0)
+	for _, pair := range pairs {
 		kv := kvstruct.KV{
-			Key:   k,
-			Value: v,
+			Key:   pair.Key,
+			Value: pair.Value,
 		}
 		kvs.Add(kv)
 	}
@@ -161,6 +443,148 @@ func parserOneLine(line string) (kvs kvstruct.KVS) {
 	})
 	return kvs
 }
+
+// strictChecker 严格模式下的检查,记录已出现的fullname、读取来源(src)、写入目标(dst)所在行
+type strictChecker struct {
+	fullnames map[string]int
+	targets   map[string]int
+}
+
+func newStrictChecker() (checker *strictChecker) {
+	return &strictChecker{
+		fullnames: make(map[string]int),
+		targets:   make(map[string]int),
+	}
+}
+
+// checkPairs 检查未知key(含被合并到值中的疑似key)及枚举值格式
+func (checker *strictChecker) checkPairs(pairs []linePair) (parseErrors []*ParseError) {
+	parseErrors = make([]*ParseError, 0)
+	for _, pair := range pairs {
+		if !isTokenKey(pair.Key) {
+			parseErrors = append(parseErrors, newUnknownKeyError(pair.Key, pair.Column))
+			continue
+		}
+		switch pair.Key {
+		case "enum", "enumNames":
+			if !strings.HasPrefix(pair.Value, "[") || !json.Valid([]byte(pair.Value)) {
+				parseError := newParseError(PARSE_ERROR_CODE_INVALID_VALUE, pair.Key, fmt.Sprintf("%s must be json array,got:%s", pair.Key, pair.Value))
+				parseErrors = append(parseErrors, parseError)
+			}
+		}
+		for _, seg := range pair.Merged {
+			key := seg.Text
+			index := strings.IndexRune(key, TOKEN_END)
+			if index > -1 {
+				key = key[:index]
+			}
+			if !isKeyName(key) {
+				continue
+			}
+			if index < 0 && suggestToken(key) == "" { // 单独的单词,只有和token相似时才认为是写错的key,其它视为值中的逗号
+				continue
+			}
+			parseErrors = append(parseErrors, newUnknownKeyError(key, seg.Column))
+		}
+	}
+	return parseErrors
+}
+
+// checkItem 检查重复fullname、dst冲突以及没有边界的 exclusiveMaximum、exclusiveMinimum(不产生约束,转换为jsonschema时丢弃):
+// 任意方向多行写入同一dst均为冲突,多行读取同一src(一对多)是合法的
+func (checker *strictChecker) checkItem(meta *Meta, item *JsonschemalineItem, lineNo int) (parseErrors []*ParseError) {
+	parseErrors = make([]*ParseError, 0)
+	if item.ExclusiveMaximum && item.Maximum == nil {
+		parseErrors = append(parseErrors, newParseError(PARSE_ERROR_CODE_INVALID_VALUE, "exclusiveMaximum", "exclusiveMaximum requires maximum"))
+	}
+	if item.ExclusiveMinimum && item.Minimum == nil {
+		parseErrors = append(parseErrors, newParseError(PARSE_ERROR_CODE_INVALID_VALUE, "exclusiveMinimum", "exclusiveMinimum requires minimum"))
+	}
+	if first, ok := checker.fullnames[item.Fullname]; ok {
+		parseError := newParseError(PARSE_ERROR_CODE_DUPLICATE_FULLNAME, "fullname", fmt.Sprintf("duplicate fullname %s, first defined at line %d", item.Fullname, first))
+		parseErrors = append(parseErrors, parseError)
+	} else {
+		checker.fullnames[item.Fullname] = lineNo
+	}
+	if meta == nil {
+		return parseErrors
+	}
+	if parseError := checkConflict(checker.targets, "dst", item.Dst, lineNo); parseError != nil {
+		parseErrors = append(parseErrors, parseError)
+	}
+	return parseErrors
+}
+
+// checkConflict path 已被其它行使用时返回冲突错误,否则记录所在行;模板值不检查
+func checkConflict(lines map[string]int, key string, path string, lineNo int) (parseError *ParseError) {
+	if isTplValue(path) {
+		return nil
+	}
+	if first, ok := lines[path]; ok {
+		return newParseError(PARSE_ERROR_CODE_SRC_DST_CONFLICT, key, fmt.Sprintf("%s %s already written by line %d", key, path, first))
+	}
+	lines[path] = lineNo
+	return nil
+}
+
+func newUnknownKeyError(key string, column int) (parseError *ParseError) {
+	msg := fmt.Sprintf("unknown key %s", key)
+	suggestion := suggestToken(key)
+	if suggestion != "" {
+		msg = fmt.Sprintf("%s, did you mean %s?", msg, suggestion)
+	}
+	parseError = newParseError(PARSE_ERROR_CODE_UNKNOWN_KEY, key, msg)
+	parseError.Column = column
+	parseError.Suggestion = suggestion
+	return parseError
+}
+
+// suggestToken 找到和key最相近的token,编辑距离超过2时返回空
+func suggestToken(key string) (suggestion string) {
+	minDistance := 3
+	for _, token := range getTokens() {
+		distance := levenshtein(strings.ToLower(key), strings.ToLower(token))
+		if distance < minDistance {
+			minDistance, suggestion = distance, token
+		}
+	}
+	return suggestion
+}
+
+func isKeyName(s string) (yes bool) {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_'
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isTplValue src/dst 为模板的情况,如 {{setValue . "Offset" 0}}
+func isTplValue(s string) (yes bool) {
+	return strings.HasPrefix(s, "{{")
+}
+
+// isTokenKey key 是否为lineschema支持的key
+func isTokenKey(key string) (yes bool) {
+	getTokens()
+	_, yes = tokenSet[key]
+	return yes
+}
+
+func inArray(s string, arr []string) (yes bool) {
+	for _, v := range arr {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func isToken(s string) (yes bool) {
 	for _, token := range getTokens() {
 		yes = strings.HasPrefix(s, token)
@@ -171,16 +595,28 @@ func isToken(s string) (yes bool) {
 	return false
 }
 
-func getTokens() (tokens []string) {
-	tokens = make([]string, 0)
-	meta := new(Meta)
-	var rt reflect.Type
-	rt = reflect.TypeOf(meta).Elem()
-	tokens = append(tokens, getJsonTagname(rt)...)
-	item := new(JsonschemalineItem)
-	rt = reflect.TypeOf(item).Elem()
-	tokens = append(tokens, getJsonTagname(rt)...)
+var (
+	tokens     []string
+	tokenSet   map[string]struct{}
+	tokensOnce sync.Once
+)
 
+// getTokens Meta、JsonschemalineItem 的json tag名,只反射一次,返回值只读
+func getTokens() []string {
+	tokensOnce.Do(func() {
+		tokens = make([]string, 0)
+		meta := new(Meta)
+		var rt reflect.Type
+		rt = reflect.TypeOf(meta).Elem()
+		tokens = append(tokens, getJsonTagname(rt)...)
+		item := new(JsonschemalineItem)
+		rt = reflect.TypeOf(item).Elem()
+		tokens = append(tokens, getJsonTagname(rt)...)
+		tokenSet = make(map[string]struct{}, len(tokens))
+		for _, token := range tokens {
+			tokenSet[token] = struct{}{}
+		}
+	})
 	return tokens
 }
 
@@ -3,6 +3,8 @@ package jsonschemaline
 import (
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetTokens(t *testing.T) {
@@ -16,3 +18,25 @@ func TestParserLine(t *testing.T) {
 	`
 	parserOneLine(line)
 }
+
+func TestParserLineQuoted(t *testing.T) {
+	line := `fullname=name, title="Order list",pattern="^a b,c=d$", description="say \"hi\"\n\d+",dst=name`
+	m := parserOneLine(line).Map()
+	assert.Equal(t, "Order list", m["title"])
+	assert.Equal(t, "^a b,c=d$", m["pattern"])
+	assert.Equal(t, "say \"hi\"\n\\d+", m["description"])
+	assert.Equal(t, "name", m["dst"])
+
+	for _, value := range []string{"Order list", `"quoted"`, "a,title=b", `C:\path\n`, "plain", "a,b"} {
+		m := parserOneLine(fmt.Sprintf("title=%s", quoteValue(value))).Map()
+		assert.Equal(t, value, m["title"])
+	}
+}
+
+func TestParserLineTemplate(t *testing.T) {
+	tpl := `{{setValue . "Offset" (mul (getValue . "in.pageIndex") (getValue . "in.pageSize"))}}`
+	m := parserOneLine(fmt.Sprintf("fullname=pageIndex,dst=%s,required", tpl)).Map()
+	assert.Equal(t, tpl, m["dst"])
+	assert.Equal(t, "true", m["required"])
+	assert.Equal(t, tpl, quoteValue(tpl))
+}
@@ -0,0 +1,150 @@
+package jsonschemaline
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LossyKeyword 转换过程中有意不保留(或隐式表达)的关键字及原因
+type LossyKeyword struct {
+	Keyword string
+	Reason  string
+}
+
+// TextLossyKeywords 文本→Jsonschemaline→文本 过程中不保留的内容,规范形式见 Jsonschemaline.String
+var TextLossyKeywords = []LossyKeyword{
+	{Keyword: "type=string", Reason: "字符串为默认类型,不输出,解析时自动补全"},
+	{Keyword: "src", Reason: "direction=in 时与fullname推导值相同则不输出,解析时自动补全"},
+	{Keyword: "dst", Reason: "direction=out 时与fullname推导值相同则不输出,解析时自动补全"},
+	{Keyword: "false/0/空值", Reason: "和未填写等价,不输出"},
+	{Keyword: "meta", Reason: "元数据行只保留version、direction、id,并按此顺序输出"},
+	{Keyword: "空白", Reason: "引号外的空白、空行不保留,需要保留空白时使用引号形式"},
+}
+
+// JsonSchemaLossyKeywords Jsonschemaline→JSON Schema→Jsonschemaline 过程中丢失的内容
+var JsonSchemaLossyKeywords = []LossyKeyword{
+	{Keyword: "id", Reason: "JSON Schema 不输出$id,转换回来时为example"},
+	{Keyword: "version", Reason: "非 JsonSchemaWithDraft 支持的draft时,JSON Schema 输出draft-07的$schema"},
+	{Keyword: "direction", Reason: "JSON Schema 只描述数据结构,转换回来时为in"},
+	{Keyword: "src", Reason: "数据映射关系,JSON Schema 无对应关键字"},
+	{Keyword: "dst", Reason: "数据映射关系,JSON Schema 无对应关键字,转换回来时由fullname推导"},
+	{Keyword: "#、//注释", Reason: "JSON Schema 无对应结构"},
+	{Keyword: "object/array 容器行", Reason: "除type外没有其它关键字且存在子节点时,由子节点隐含表达"},
+}
+
+// RoundTripText 验证 文本→Jsonschemaline→文本 无损,返回规范形式
+func RoundTripText(lineschema string) (canonical string, err error) {
+	first, err := ParseJsonschemaline(lineschema)
+	if err != nil {
+		return "", err
+	}
+	canonical = first.String()
+	second, err := ParseJsonschemaline(canonical)
+	if err != nil {
+		return "", errors.WithMessage(err, "parse canonical lineschema")
+	}
+	if again := second.String(); again != canonical {
+		err = errors.Errorf("canonical lineschema changed after round trip,\nfirst:\n%s\nsecond:\n%s", canonical, again)
+		return "", err
+	}
+	err = compareItems(first.Items, second.Items, nil)
+	if err != nil {
+		return "", err
+	}
+	return canonical, nil
+}
+
+// RoundTripJsonSchema 验证 lineschema→JSON Schema→lineschema 除 JsonSchemaLossyKeywords 外无损,返回生成的JSON Schema
+func RoundTripJsonSchema(lineschema string) (jsonschema []byte, err error) {
+	l, err := ParseJsonschemaline(lineschema)
+	if err != nil {
+		return nil, err
+	}
+	jsonschema, err = l.JsonSchema()
+	if err != nil {
+		return nil, err
+	}
+	back, err := JsonSchema2LineSchema(string(jsonschema))
+	if err != nil {
+		return nil, errors.WithMessage(err, "convert json schema back to lineschema")
+	}
+	items := make(JsonschemalineItems, 0)
+	for _, item := range l.Items {
+		if !l.isImpliedContainer(item) {
+			items = append(items, item)
+		}
+	}
+	err = compareItems(items, back.Items, []string{"src", "dst"})
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema, nil
+}
+
+// isImpliedContainer 除type外没有其它关键字、且存在子节点的object/array行
+func (l *Jsonschemaline) isImpliedContainer(item *JsonschemalineItem) (yes bool) {
+	if item.Type != "object" && item.Type != "array" {
+		return false
+	}
+	keywords := itemKeywords(item, []string{"fullname", "src", "dst", "type"})
+	if len(keywords) > 0 {
+		return false
+	}
+	for _, other := range l.Items {
+		if strings.HasPrefix(other.Fullname, item.Fullname+".") || strings.HasPrefix(other.Fullname, item.Fullname+"[]") {
+			return true
+		}
+	}
+	return false
+}
+
+// compareItems 按fullname比较两组item的关键字,ignoreKeys 不参与比较
+func compareItems(expected JsonschemalineItems, actual JsonschemalineItems, ignoreKeys []string) (err error) {
+	actualMap := make(map[string]*JsonschemalineItem)
+	for _, item := range actual {
+		actualMap[item.Fullname] = item
+	}
+	if len(expected) != len(actual) {
+		err = errors.Errorf("round trip item count changed,expected:%d,got:%d", len(expected), len(actual))
+		return err
+	}
+	for _, item := range expected {
+		other, ok := actualMap[item.Fullname]
+		if !ok {
+			err = errors.Errorf("round trip lost fullname %s", item.Fullname)
+			return err
+		}
+		expectedKeywords, actualKeywords := itemKeywords(item, ignoreKeys), itemKeywords(other, ignoreKeys)
+		keys := make([]string, 0)
+		for k := range expectedKeywords {
+			keys = append(keys, k)
+		}
+		for k := range actualKeywords {
+			if _, ok := expectedKeywords[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if expectedKeywords[k] != actualKeywords[k] {
+				err = errors.Errorf("round trip changed %s of fullname %s,expected:%s,got:%s", k, item.Fullname, expectedKeywords[k], actualKeywords[k])
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// itemKeywords item 已填写的关键字,值为lineschema中的字符串形式
+func itemKeywords(item *JsonschemalineItem, ignoreKeys []string) (keywords map[string]string) {
+	keywords = make(map[string]string)
+	b, _ := json.Marshal(item)
+	_ = json.Unmarshal(b, &keywords)
+	for _, k := range ignoreKeys {
+		delete(keywords, k)
+	}
+	return keywords
+}
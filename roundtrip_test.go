@@ -0,0 +1,46 @@
+package jsonschemaline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+// allKeywordsLineschema 覆盖所有支持的关键字
+var allKeywordsLineschema = `
+# 全部关键字
+version=http://json-schema.org/draft-07/schema#,direction=in,id=allKeywords
+fullname=pageIndex,dst=offset,type=int,format=number,required,title=页码,description="从0开始",default=0,comment=备注,example=1,examples=[1,2],minimum=0,exclusiveMinimum,maximum=100,exclusiveMaximum,multipleOf=1
+fullname=status,dst=status,enum=["1","2"],enumNames=["启用","禁用"],const=1,deprecated,readOnly,writeOnly,allowEmptyValue
+fullname=name,dst=name,pattern="^a b$",minLength=1,maxLength=20,contentEncoding=base64,contentMediaType=text/plain
+fullname=tags,dst=tags,type=array,format=string,minItems=1,maxItems=3,uniqueItems,minContains=1,maxContains=2
+fullname=pagination,dst=pagination,type=object,title=分页,minProperties=1,maxProperties=3
+fullname=pagination.size,dst=pagination.size,type=int,required // 每页数量
+fullname=items,dst=items,type=array
+fullname=items[].id,dst=items.#.id,required
+fullname=ids[],dst=ids.#,type=int
+`
+
+func TestRoundTripText(t *testing.T) {
+	canonical, err := jsonschemaline.RoundTripText(allKeywordsLineschema)
+	require.NoError(t, err)
+	again, err := jsonschemaline.RoundTripText(canonical)
+	require.NoError(t, err)
+	assert.Equal(t, canonical, again)
+
+	t.Run("src_dst", func(t *testing.T) {
+		lineschema := `version=http://json-schema.org/draft-07/schema#,direction=out,id=out
+fullname=id,src=Fid
+fullname=name,src=name,dst=user.name`
+		canonical, err := jsonschemaline.RoundTripText(lineschema)
+		require.NoError(t, err)
+		assert.Equal(t, lineschema, canonical)
+	})
+}
+
+func TestRoundTripJsonSchema(t *testing.T) {
+	_, err := jsonschemaline.RoundTripJsonSchema(allKeywordsLineschema)
+	require.NoError(t, err)
+}
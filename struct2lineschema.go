@@ -0,0 +1,212 @@
+package jsonschemaline
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/suifengpiao14/kvstruct"
+)
+
+const (
+	STRUCT_TAG_LINESCHEMA = "lineschema" // 结构体字段中补充lineschema的tag,按一行lineschema书写,如 lineschema:"title=名称,format=int,minimum=1"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Struct2lineschema go结构体转lineschema,v 为结构体、结构体指针或其 reflect.Type:
+//   - fullname 取自json tag(无tag时为字段名),json:"-"、lineschema:"-"、未导出字段忽略,匿名嵌入结构体展开到上级
+//   - 非指针且没有omitempty的字段为必填
+//   - 嵌套结构体、切片、指针按层级生成 a.b、a[].b 形式的fullname
+//   - lineschema tag 中的 key=value 覆盖自动推导的值
+func Struct2lineschema(id string, direction string, v interface{}) (lineschema *Jsonschemaline, err error) {
+	rt, ok := v.(reflect.Type)
+	if !ok {
+		rt = reflect.TypeOf(v)
+	}
+	if rt == nil {
+		err = errors.New("struct2lineschema: nil value")
+		return nil, err
+	}
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		err = errors.Errorf("struct2lineschema: want struct,got:%s", rt.String())
+		return nil, err
+	}
+	meta := &Meta{
+		Version:   "http://json-schema.org/draft-07/schema#",
+		ID:        id,
+		Direction: direction,
+	}
+	if err = validMeta(meta); err != nil {
+		return nil, err
+	}
+	lineschema = &Jsonschemaline{
+		Meta:  meta,
+		Items: make(JsonschemalineItems, 0),
+	}
+	parser := &structParser{
+		lineschema: lineschema,
+		visiting:   make(map[reflect.Type]bool),
+	}
+	err = parser.parseStruct(rt, "")
+	if err != nil {
+		return nil, err
+	}
+	return lineschema, nil
+}
+
+// structParser 记录正在解析的结构体,避免递归类型死循环
+type structParser struct {
+	lineschema *Jsonschemaline
+	visiting   map[reflect.Type]bool
+}
+
+func (p *structParser) parseStruct(rt reflect.Type, prefix string) (err error) {
+	if p.visiting[rt] {
+		err = errors.Errorf("struct2lineschema: recursive type %s not supported", rt.String())
+		return err
+	}
+	p.visiting[rt] = true
+	defer delete(p.visiting, rt)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous { // 未导出
+			continue
+		}
+		lineTag := field.Tag.Get(STRUCT_TAG_LINESCHEMA)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" || lineTag == "-" {
+			continue
+		}
+		opts := strings.Split(jsonTag, ",")
+		name, omitempty := opts[0], inArray("omitempty", opts[1:])
+		fieldType := field.Type
+		required := !omitempty
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+			required = false
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct { // 匿名嵌入,按json规则展开
+			if err = p.parseStruct(fieldType, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fullname := name
+		if prefix != "" {
+			fullname = fmt.Sprintf("%s.%s", prefix, name)
+		}
+		if err = p.parseField(fieldType, fullname, required, lineTag); err != nil {
+			return errors.WithMessagef(err, "field %s.%s", rt.String(), field.Name)
+		}
+	}
+	return nil
+}
+
+// parseField 生成字段所在行,再按类型处理子节点
+func (p *structParser) parseField(rt reflect.Type, fullname string, required bool, lineTag string) (err error) {
+	typ, format := structFieldType(rt)
+	kvs := kvstruct.KVS{
+		{Key: "fullname", Value: fullname},
+		{Key: "src", Value: defaultSrcOrDst(fullname)},
+		{Key: "dst", Value: defaultSrcOrDst(fullname)},
+	}
+	if typ != "" {
+		kvs.AddReplace(kvstruct.KV{Key: "type", Value: typ})
+	}
+	if format != "" {
+		kvs.AddReplace(kvstruct.KV{Key: "format", Value: format})
+	}
+	if required {
+		kvs.AddReplace(kvstruct.KV{Key: "required", Value: "true"})
+	}
+	if lineTag != "" {
+		pairs, _ := scanLine(lineTag)
+		for _, pair := range pairs {
+			if !isTokenKey(pair.Key) {
+				return newUnknownKeyError(pair.Key, pair.Column)
+			}
+			kvs.AddReplace(kvstruct.KV{Key: pair.Key, Value: pair.Value})
+		}
+	}
+	item, err := parseItem(kvs)
+	if err != nil {
+		return err
+	}
+	item.Lineschema = p.lineschema
+	p.lineschema.Items = append(p.lineschema.Items, item)
+
+	switch rt.Kind() {
+	case reflect.Struct:
+		if rt == timeType {
+			return nil
+		}
+		return p.parseStruct(rt, fullname)
+	case reflect.Slice, reflect.Array:
+		if rt == rawMessageType || rt.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		elem := rt.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		switch elem.Kind() {
+		case reflect.Struct:
+			if elem == timeType {
+				return nil
+			}
+			return p.parseStruct(elem, fmt.Sprintf("%s[]", fullname))
+		case reflect.Slice, reflect.Array:
+			return p.parseField(elem, fmt.Sprintf("%s[]", fullname), false, "")
+		}
+	}
+	return nil
+}
+
+// structFieldType go类型对应的type、format,切片的format为元素的format
+func structFieldType(rt reflect.Type) (typ string, format string) {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt {
+	case timeType:
+		return "string", "date-time"
+	case rawMessageType:
+		return "", ""
+	}
+	switch rt.Kind() {
+	case reflect.String:
+		return "string", ""
+	case reflect.Bool:
+		return "boolean", "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", "int"
+	case reflect.Float32, reflect.Float64:
+		return "number", "float"
+	case reflect.Struct, reflect.Map:
+		return "object", ""
+	case reflect.Slice, reflect.Array:
+		if rt.Elem().Kind() == reflect.Uint8 { // []byte 按json规则为base64字符串
+			return "string", ""
+		}
+		_, format = structFieldType(rt.Elem())
+		return "array", format
+	}
+	return "", ""
+}
@@ -0,0 +1,90 @@
+package jsonschemaline_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+type struct2lineschemaPagination struct {
+	Index int `json:"index" lineschema:"title=页索引,minimum=1"`
+	Size  int `json:"size" lineschema:"title=每页数量,maximum=100"`
+}
+
+type struct2lineschemaItem struct {
+	ID        int64     `json:"id"`
+	Status    string    `json:"status" lineschema:"enum=[\"on\",\"off\"],description=\"状态, on-启用\""`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type struct2lineschemaOut struct {
+	struct2lineschemaPagination
+	Items    []*struct2lineschemaItem `json:"items"`
+	Price    *float64                 `json:"price"`
+	Enabled  bool                     `json:"enabled,omitempty"`
+	Extra    map[string]string        `json:"extra,omitempty"`
+	Password string                   `json:"-"`
+	internal string
+}
+
+func TestStruct2lineschema(t *testing.T) {
+	lineschema, err := jsonschemaline.Struct2lineschema("list", jsonschemaline.LINE_SCHEMA_DIRECTION_OUT, &struct2lineschemaOut{})
+	require.NoError(t, err)
+	expected := `version=http://json-schema.org/draft-07/schema#,direction=out,id=list
+fullname=index,src=index,type=integer,format=int,required,title=页索引,minimum=1
+fullname=size,src=size,type=integer,format=int,required,title=每页数量,maximum=100
+fullname=items,src=items,type=array,required
+fullname=items[].id,src=items.#.id,type=integer,format=int,required
+fullname=items[].status,src=items.#.status,enum=["on","off"],required,description="状态, on-启用"
+fullname=items[].tags,src=items.#.tags,type=array
+fullname=items[].createdAt,src=items.#.createdAt,format=date-time,required
+fullname=price,src=price,type=number,format=float
+fullname=enabled,src=enabled,type=boolean,format=bool
+fullname=extra,src=extra,type=object`
+	assert.Equal(t, expected, lineschema.String())
+
+	byType, err := jsonschemaline.Struct2lineschema("list", jsonschemaline.LINE_SCHEMA_DIRECTION_OUT, reflect.TypeOf(struct2lineschemaOut{}))
+	require.NoError(t, err)
+	assert.Equal(t, expected, byType.String())
+
+	_, err = lineschema.JsonSchema()
+	require.NoError(t, err)
+	reparsed, err := jsonschemaline.ParseJsonschemaline(lineschema.String())
+	require.NoError(t, err)
+	assert.Equal(t, expected, reparsed.String())
+
+	price := 9.9
+	out := struct2lineschemaOut{
+		struct2lineschemaPagination: struct2lineschemaPagination{Index: 1, Size: 10},
+		Items:                       []*struct2lineschemaItem{{ID: 1, Status: "on", Tags: []string{"a"}, CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		Price:                       &price,
+	}
+	data, err := json.Marshal(out)
+	require.NoError(t, err)
+	assert.Empty(t, lineschema.Validate(data))
+}
+
+type struct2lineschemaNode struct {
+	Children []struct2lineschemaNode `json:"children"`
+}
+
+func TestStruct2lineschemaError(t *testing.T) {
+	_, err := jsonschemaline.Struct2lineschema("node", jsonschemaline.LINE_SCHEMA_DIRECTION_IN, struct2lineschemaNode{})
+	require.Error(t, err)
+
+	_, err = jsonschemaline.Struct2lineschema("list", jsonschemaline.LINE_SCHEMA_DIRECTION_IN, 1)
+	require.Error(t, err)
+
+	type badTag struct {
+		Name string `json:"name" lineschema:"titel=名称"`
+	}
+	_, err = jsonschemaline.Struct2lineschema("list", jsonschemaline.LINE_SCHEMA_DIRECTION_IN, badTag{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did you mean title")
+}
@@ -0,0 +1,155 @@
+package jsonschemaline
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Transformer 按 src→dst 映射直接转换json数据,由 NewTransformer 生成,生成后只读,可并发使用
+type Transformer struct {
+	ID        string
+	Direction string
+	mappings  []transformMapping
+}
+
+// transformMapping 一行映射,srcParts、dstParts 为按 .# 切分后的路径片段
+type transformMapping struct {
+	fullname string
+	srcParts []string
+	dstParts []string
+	dflt     string
+	format   string
+	coerce   func(value string) (coerced interface{}, err error) // 入参、内部转换时按format转换
+	toString bool                                                // 出参 type=string 时,转换为字符串
+}
+
+// NewTransformer 编译映射规则:
+//   - src、dst 为模板({{...}})的行不参与转换,由 InstructTpl 处理
+//   - 存在子节点的 object/array 行由子节点表达;type=array 且有format时,按元素转换
+//   - src、dst 中 .# 的个数必须一致,支持多层嵌套数组
+func NewTransformer(l *Jsonschemaline) (transformer *Transformer, err error) {
+	transformer = &Transformer{
+		ID:        l.Meta.ID,
+		Direction: l.Meta.Direction,
+		mappings:  make([]transformMapping, 0),
+	}
+	for _, item := range l.Items {
+		if isTplValue(item.Src) || isTplValue(item.Dst) || item.Src == "" || item.Dst == "" {
+			continue
+		}
+		src, dst := item.Src, item.Dst
+		switch strings.ToLower(item.Type) {
+		case "object", "array":
+			if l.hasChildren(item) {
+				continue
+			}
+			if strings.ToLower(item.Type) == "array" && item.Format != "" { // 解决 ids=[1,3,4] 情况
+				src, dst = fmt.Sprintf("%s.#", src), fmt.Sprintf("%s.#", dst)
+			}
+		}
+		mapping := transformMapping{
+			fullname: item.Fullname,
+			srcParts: strings.Split(src, ".#"),
+			dstParts: strings.Split(dst, ".#"),
+			dflt:     item.Default,
+			format:   item.Format,
+		}
+		if len(mapping.srcParts) != len(mapping.dstParts) {
+			err = errors.Errorf("fullname %s: src %s and dst %s must have the same number of .#", item.Fullname, item.Src, item.Dst)
+			return nil, err
+		}
+		switch l.Meta.Direction {
+		case LINE_SCHEMA_DIRECTION_OUT:
+			mapping.toString = item.Type == "string"
+		default:
+			if f, ok := GetFormat(item.Format); ok {
+				mapping.coerce = f.Coerce
+			}
+		}
+		transformer.mappings = append(transformer.mappings, mapping)
+	}
+	return transformer, nil
+}
+
+// hasChildren 是否存在以item为父节点的行
+func (l *Jsonschemaline) hasChildren(item *JsonschemalineItem) (yes bool) {
+	for _, other := range l.Items {
+		if strings.HasPrefix(other.Fullname, item.Fullname+".") || strings.HasPrefix(other.Fullname, item.Fullname+"[]") {
+			return true
+		}
+	}
+	return false
+}
+
+// Transform 转换json数据,src 不存在时使用default,没有default则不输出该字段;
+// format 转换失败的值保持原样,记录在返回的 Violations 中(错误码 VALIDATE_ERROR_CODE_FORMAT)
+func (t *Transformer) Transform(input []byte) (output []byte, err error) {
+	if !gjson.ValidBytes(input) {
+		err = errors.Errorf("invalid json: %s", string(input))
+		return nil, err
+	}
+	root := gjson.ParseBytes(input)
+	violations := make(Violations, 0)
+	for _, mapping := range t.mappings {
+		output, err = mapping.transform(root, output, "", mapping.srcParts, mapping.dstParts, &violations)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(output) == 0 {
+		output = []byte("{}")
+	}
+	if len(violations) > 0 {
+		return output, violations
+	}
+	return output, nil
+}
+
+// transform 逐层展开数组,node 为当前src节点,dstPrefix 为已展开的dst路径
+func (m transformMapping) transform(node gjson.Result, output []byte, dstPrefix string, srcParts []string, dstParts []string, violations *Violations) (newOutput []byte, err error) {
+	value := node
+	if srcPath := strings.TrimPrefix(srcParts[0], "."); srcPath != "" {
+		value = node.Get(srcPath)
+	}
+	dstPath := dstPrefix + dstParts[0] // 除第一段外,片段均以 . 开头或为空
+	if len(srcParts) > 1 {
+		if !value.IsArray() {
+			return output, nil
+		}
+		elements := value.Array()
+		if len(elements) == 0 && !gjson.GetBytes(output, dstPath).Exists() {
+			return sjson.SetRawBytes(output, dstPath, []byte("[]"))
+		}
+		for i, element := range elements {
+			output, err = m.transform(element, output, fmt.Sprintf("%s.%d", dstPath, i), srcParts[1:], dstParts[1:], violations)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return output, nil
+	}
+
+	if !value.Exists() || value.Type == gjson.Null {
+		if m.dflt == "" {
+			return output, nil
+		}
+		value = gjson.Result{Type: gjson.String, Str: m.dflt, Raw: strconv.Quote(m.dflt)}
+	}
+	switch {
+	case m.coerce != nil && value.Type == gjson.String:
+		coerced, err := m.coerce(value.Str)
+		if err != nil {
+			*violations = append(*violations, &Violation{Path: dstPath, Fullname: m.fullname, Code: VALIDATE_ERROR_CODE_FORMAT, Msg: fmt.Sprintf("can not coerce %q to %s", value.Str, m.format)})
+			return sjson.SetRawBytes(output, dstPath, []byte(value.Raw))
+		}
+		return sjson.SetBytes(output, dstPath, coerced)
+	case m.toString && (value.Type == gjson.Number || value.Type == gjson.True || value.Type == gjson.False):
+		return sjson.SetBytes(output, dstPath, value.String())
+	}
+	return sjson.SetRawBytes(output, dstPath, []byte(value.Raw))
+}
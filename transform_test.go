@@ -0,0 +1,64 @@
+package jsonschemaline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestTransformer(t *testing.T) {
+	t.Run("in", func(t *testing.T) {
+		lineschema, err := jsonschemaline.ParseJsonschemaline(schemalineIn2)
+		require.NoError(t, err)
+		transformer, err := jsonschemaline.NewTransformer(lineschema)
+		require.NoError(t, err)
+		output, err := transformer.Transform([]byte(`{"pageSize":"20","pageIndex":"2"}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"Limit":20}`, string(output)) // 模板映射由 InstructTpl 处理
+	})
+
+	t.Run("out", func(t *testing.T) {
+		lineschema, err := jsonschemaline.ParseJsonschemaline(schemalineOut)
+		require.NoError(t, err)
+		transformer, err := jsonschemaline.NewTransformer(lineschema)
+		require.NoError(t, err)
+		input := `{"PaginateOut":[{"Fid":1,"Fopen_id":"a","Fopen_id_type":2,"Fstatus":true},{"Fid":2,"Fopen_id":"b"}],"input":{"pageIndex":"0","pageSize":"20"},"PaginateTotalOut":2}`
+		output, err := transformer.Transform([]byte(input))
+		require.NoError(t, err)
+		expected := `{"items":[{"id":"1","openId":"a","type":"2","status":"true"},{"id":"2","openId":"b"}],"pageInfo":{"pageIndex":"0","pageSize":"20","total":"2"}}`
+		assert.Equal(t, expected, string(output))
+	})
+
+	t.Run("nested array and default", func(t *testing.T) {
+		line := `version=http://json-schema.org/draft-07/schema#,direction=convert,id=order
+fullname=orders[].id,src=Forders.#.Fid,dst=orders.#.id,format=int
+fullname=orders[].goods[].name,src=Forders.#.Fgoods.#.Fname,dst=orders.#.goods.#.name
+fullname=orders[].goods[].count,src=Forders.#.Fgoods.#.Fcount,dst=orders.#.goods.#.count,format=int,default=1
+fullname=tags,src=Ftags,dst=tags,type=array,format=int
+fullname=remark,src=Fremark,dst=remark`
+		lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+		require.NoError(t, err)
+		transformer, err := jsonschemaline.NewTransformer(lineschema)
+		require.NoError(t, err)
+		input := `{"Forders":[{"Fid":"1","Fgoods":[{"Fname":"pen","Fcount":"3"},{"Fname":"book"}]},{"Fid":"2","Fgoods":[]}],"Ftags":["1","2"]}`
+		output, err := transformer.Transform([]byte(input))
+		require.NoError(t, err)
+		expected := `{"orders":[{"id":1,"goods":[{"name":"pen","count":3},{"name":"book","count":1}]},{"id":2,"goods":[]}],"tags":[1,2]}`
+		assert.Equal(t, expected, string(output))
+
+		output, err = transformer.Transform([]byte(`{"Forders":[{"Fid":"x"}]}`))
+		require.Error(t, err)
+		assert.Equal(t, `{"orders":[{"id":"x"}]}`, string(output))
+	})
+
+	t.Run("mismatched array", func(t *testing.T) {
+		line := `version=http://json-schema.org/draft-07/schema#,direction=convert,id=order
+fullname=ids,src=Forders.#.Fid,dst=ids`
+		lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+		require.NoError(t, err)
+		_, err = jsonschemaline.NewTransformer(lineschema)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,166 @@
+package jsonschemaline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// tsGoTypes go类型对应的TypeScript类型
+var tsGoTypes = map[string]string{
+	"string":          "string",
+	"bool":            "boolean",
+	"interface{}":     "unknown",
+	"time.Time":       "string",
+	"json.RawMessage": "unknown",
+}
+
+// TypeScript 生成TypeScript类型定义,名称与 ToSturct 的结构体一致
+func (l *Jsonschemaline) TypeScript() (source string, err error) {
+	return l.TypeScriptWithOptions(StructOptions{})
+}
+
+// TypeScriptWithOptions 按 ToSturctWithOptions 的命名、类型映射生成TypeScript类型定义:
+//   - 结构体生成 interface,数组生成 type 别名,root 排在最前,其余按名称排序
+//   - 属性名为json数据中的key(fullname的最后一段),如 content-type、created_at,不是go结构体的json tag
+//   - 非必填字段为可选属性(name?: T),没有对应行的对象字段视为非必填
+//   - enum 生成字面量联合类型
+//   - Title、Description、Comment 生成 JSDoc
+func (l *Jsonschemaline) TypeScriptWithOptions(options StructOptions) (source string, err error) {
+	structs := l.toSturct(options, true) // 需要 StructAttr.Rule 中的 required、enum
+	sort.SliceStable(structs, func(i, j int) bool {
+		if structs[i].IsRoot != structs[j].IsRoot {
+			return structs[i].IsRoot
+		}
+		return structs[i].Name < structs[j].Name
+	})
+	structsByName := make(map[string]*Struct, len(structs))
+	for _, struc := range structs {
+		structsByName[struc.Name] = struc
+	}
+	var w bytes.Buffer
+	w.WriteString("// Code generated by jsonschemaline. DO NOT EDIT.\n")
+	for _, struc := range structs {
+		w.WriteString("\n")
+		if struc.Type != "" {
+			typ, err := tsType(struc.Type, "", structsByName)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&w, "export type %s = %s;\n", struc.Name, typ)
+			continue
+		}
+		fmt.Fprintf(&w, "export interface %s {\n", struc.Name)
+		for _, attr := range struc.Attrs {
+			enum := ""
+			optional := "?"
+			if attr.Rule != nil {
+				enum = attr.Rule.Enum
+				if attr.Rule.Required {
+					optional = ""
+				}
+			}
+			typ, err := tsType(attr.Type, enum, structsByName)
+			if err != nil {
+				return "", errors.WithMessagef(err, "%s.%s", struc.Name, attr.Name)
+			}
+			w.WriteString(tsDoc(attr.docLines(), "  "))
+			fmt.Fprintf(&w, "  %s%s: %s;\n", tsPropertyName(attr.wireName()), optional, typ)
+		}
+		w.WriteString("}\n")
+	}
+	return w.String(), nil
+}
+
+// tsType go类型转换为TypeScript类型,enum 不为空时标量类型使用字面量联合类型
+func tsType(goType string, enum string, structsByName map[string]*Struct) (typ string, err error) {
+	goType = strings.TrimLeft(goType, "*")
+	if basic, ok := goSourceBasicTypes[goType]; ok {
+		goType = basic
+	}
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		elem, err := tsType(strings.TrimPrefix(goType, "[]"), enum, structsByName)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(elem, " ") {
+			elem = fmt.Sprintf("(%s)", elem)
+		}
+		return fmt.Sprintf("%s[]", elem), nil
+	case strings.HasPrefix(goType, "map[string]"):
+		value, err := tsType(strings.TrimPrefix(goType, "map[string]"), "", structsByName)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Record<string, %s>", value), nil
+	}
+	if _, ok := structsByName[goType]; ok {
+		return goType, nil
+	}
+	typ, ok := tsGoTypes[goType]
+	if !ok && goNumberTypes[goType] {
+		typ, ok = "number", true
+	}
+	if !ok {
+		typ = "unknown" // 自定义类型(如 decimal.Decimal)的json格式无法确定
+	}
+	if enum == "" || (typ != "string" && typ != "number") {
+		return typ, nil
+	}
+	literals, err := enumLiterals(enum)
+	if err != nil {
+		err = errors.WithMessage(err, "enum")
+		return "", err
+	}
+	values := make([]string, 0, len(literals))
+	for _, literal := range literals {
+		value := literal
+		_ = json.Unmarshal([]byte(literal), &value) // 字符串字面量去掉引号
+		if typ == "string" {
+			b, _ := json.Marshal(value)
+			value = string(b)
+		} else if _, err := strconv.ParseFloat(value, 64); err != nil { // 数值字段的枚举不是数字,不生成联合类型
+			return typ, nil
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, " | "), nil
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsPropertyName 属性名不是合法标识符时加引号,如 "content-type"
+func tsPropertyName(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	b, _ := json.Marshal(name)
+	return string(b)
+}
+
+// tsDoc 生成JSDoc,单行时写在一行内
+func tsDoc(lines []string, indent string) (doc string) {
+	if len(lines) == 0 {
+		return ""
+	}
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, "*/", `*\/`)
+	}
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/** %s */\n", indent, lines[0])
+	}
+	var w strings.Builder
+	fmt.Fprintf(&w, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(&w, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(&w, "%s */\n", indent)
+	return w.String()
+}
@@ -0,0 +1,79 @@
+package jsonschemaline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestTypeScript(t *testing.T) {
+	lineschema, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=out,id=list\n" +
+		"fullname=code,src=code,format=int,required,enum=[0,1],title=业务状态码\n" +
+		"fullname=message,src=message,title=业务提示,description=\"失败时为错误信息*/\"\n" +
+		"fullname=items,src=items,type=array,required\n" +
+		"fullname=items[].id,src=items.#.id,format=int,required\n" +
+		"fullname=items[].status,src=items.#.status,enum=[\"on\",\"off\"],required\n" +
+		"fullname=items[].tags,src=items.#.tags,type=array,format=string\n" +
+		"fullname=items[].content-type,src=items.#.contentType\n" +
+		"fullname=items[].created_at,src=items.#.createdAt,required\n" +
+		"fullname=pagination.total,src=pagination.total,type=integer,required\n" +
+		"fullname=extra,src=extra,type=object")
+	require.NoError(t, err)
+	source, err := lineschema.TypeScript()
+	require.NoError(t, err)
+	expected := `// Code generated by jsonschemaline. DO NOT EDIT.
+
+export interface List {
+  /** 业务状态码 */
+  code: 0 | 1;
+  /**
+   * 业务提示
+   * 失败时为错误信息*\/
+   */
+  message?: string;
+  items: ListItems;
+  pagination?: ListPagination;
+  extra?: Record<string, unknown>;
+}
+
+export interface ListItem {
+  id: number;
+  status: "on" | "off";
+  tags?: string[];
+  "content-type"?: string;
+  created_at: string;
+}
+
+export type ListItems = ListItem[];
+
+export interface ListPagination {
+  total: number;
+}
+`
+	assert.Equal(t, expected, source)
+
+	structs := lineschema.ToSturct()
+	for _, struc := range structs {
+		assert.Contains(t, source, " "+struc.Name+" ")
+	}
+
+	options := jsonschemaline.StructOptions{Singularize: func(name string) string { return name + "Element" }}
+	source, err = lineschema.TypeScriptWithOptions(options)
+	require.NoError(t, err)
+	assert.Contains(t, source, "export type ListItems = ListItemsElement[];\n")
+	for _, struc := range lineschema.ToSturctWithOptions(options) {
+		assert.Contains(t, source, " "+struc.Name+" ")
+	}
+
+	t.Run("required_object", func(t *testing.T) {
+		lineschema, err := jsonschemaline.ParseJsonschemaline("version=http://json-schema.org/draft-07/schema#,direction=out,id=list\n" +
+			"fullname=pagination,src=pagination,type=object,required\n" +
+			"fullname=pagination.total,src=pagination.total,type=integer,required")
+		require.NoError(t, err)
+		source, err := lineschema.TypeScript()
+		require.NoError(t, err)
+		assert.Contains(t, source, "  pagination: ListPagination;\n")
+	})
+}
@@ -22,3 +22,35 @@ func Namespace(fullname string) (namespace string) {
 	}
 	return namespace
 }
+
+// levenshtein 编辑距离
+func levenshtein(a string, b string) (distance int) {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func minInt(first int, others ...int) (min int) {
+	min = first
+	for _, v := range others {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
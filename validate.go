@@ -0,0 +1,314 @@
+package jsonschemaline
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cast"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	VALIDATE_ERROR_CODE_INVALID_JSON      = "invalid_json"      // 数据不是合法的json
+	VALIDATE_ERROR_CODE_REQUIRED          = "required"          // 缺少必填字段
+	VALIDATE_ERROR_CODE_TYPE              = "type"              // 类型不符
+	VALIDATE_ERROR_CODE_FORMAT            = "format"            // 字符串不符合format
+	VALIDATE_ERROR_CODE_ENUM              = "enum"              // 不在枚举值中
+	VALIDATE_ERROR_CODE_CONST             = "const"             // 不等于常量
+	VALIDATE_ERROR_CODE_MULTIPLE_OF       = "multiple_of"       // 不是multipleOf的倍数
+	VALIDATE_ERROR_CODE_MAXIMUM           = "maximum"           // 大于maximum
+	VALIDATE_ERROR_CODE_EXCLUSIVE_MAXIMUM = "exclusive_maximum" // 大于等于maximum
+	VALIDATE_ERROR_CODE_MINIMUM           = "minimum"           // 小于minimum
+	VALIDATE_ERROR_CODE_EXCLUSIVE_MINIMUM = "exclusive_minimum" // 小于等于minimum
+	VALIDATE_ERROR_CODE_MAX_LENGTH        = "max_length"        // 字符数大于maxLength
+	VALIDATE_ERROR_CODE_MIN_LENGTH        = "min_length"        // 字符数小于minLength
+	VALIDATE_ERROR_CODE_PATTERN           = "pattern"           // 不匹配pattern
+	VALIDATE_ERROR_CODE_MAX_ITEMS         = "max_items"         // 数组元素多于maxItems
+	VALIDATE_ERROR_CODE_MIN_ITEMS         = "min_items"         // 数组元素少于minItems
+	VALIDATE_ERROR_CODE_UNIQUE_ITEMS      = "unique_items"      // 数组元素重复
+	VALIDATE_ERROR_CODE_MAX_PROPERTIES    = "max_properties"    // 对象属性多于maxProperties
+	VALIDATE_ERROR_CODE_MIN_PROPERTIES    = "min_properties"    // 对象属性少于minProperties
+)
+
+// Violation 数据校验不通过的一处记录
+type Violation struct {
+	Path     string `json:"path"`     // gjson 路径,如 items.3.id,数据整体错误时为空
+	Fullname string `json:"fullname"` // 对应的lineschema fullname
+	Code     string `json:"code"`     // 错误码,见 VALIDATE_ERROR_CODE_*
+	Msg      string `json:"msg"`
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s(%s)", v.Path, v.Msg, v.Code)
+}
+
+// Violations 一次校验的全部记录
+type Violations []*Violation
+
+func (vs Violations) Error() string {
+	msgs := make([]string, 0, len(vs))
+	for _, v := range vs {
+		msgs = append(msgs, v.Error())
+	}
+	return strings.Join(msgs, EOF)
+}
+
+// Validate 按 fullname 描述的结构校验json数据,返回全部不通过的记录,通过时返回空切片。
+// 遵循项目"type=string,format=number"的约定:字符串类型的值按format检查能否转换,转换后的数值参与 maximum、minimum、multipleOf 校验。
+// 父节点不存在时,不再校验其子节点(父节点自身是否必填由父节点所在行决定)
+func (l *Jsonschemaline) Validate(data []byte) (violations Violations) {
+	return l.validate(data, compileValidatePatterns(l.Items))
+}
+
+// validatePattern 编译后的pattern,err 不为空时pattern不合法
+type validatePattern struct {
+	reg *regexp.Regexp
+	err error
+}
+
+// validatePatterns 以pattern为key,校验前统一编译,每个pattern只编译一次
+type validatePatterns map[string]*validatePattern
+
+func compileValidatePatterns(items JsonschemalineItems) (patterns validatePatterns) {
+	patterns = make(validatePatterns)
+	for _, item := range items {
+		if item.Pattern == "" {
+			continue
+		}
+		if _, ok := patterns[item.Pattern]; ok {
+			continue
+		}
+		reg, err := regexp.Compile(item.Pattern)
+		patterns[item.Pattern] = &validatePattern{reg: reg, err: err}
+	}
+	return patterns
+}
+
+func (l *Jsonschemaline) validate(data []byte, patterns validatePatterns) (violations Violations) {
+	violations = make(Violations, 0)
+	if !gjson.ValidBytes(data) {
+		violations = append(violations, &Violation{Code: VALIDATE_ERROR_CODE_INVALID_JSON, Msg: "invalid json"})
+		return violations
+	}
+	root := gjson.ParseBytes(data)
+	seen := make(map[string]bool)
+	for _, item := range l.Items {
+		segments := strings.Split(strings.Trim(item.Fullname, "."), ".")
+		for _, v := range item.validatePath(root, "", segments, patterns) {
+			key := fmt.Sprintf("%s|%s", v.Path, v.Code)
+			if seen[key] { // 多行共享同一个父节点时,父节点的错误只记录一次
+				continue
+			}
+			seen[key] = true
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// validatePath 沿fullname逐段查找数据,segments 为剩余的fullname片段,path 为当前节点的gjson路径
+func (jItem JsonschemalineItem) validatePath(node gjson.Result, path string, segments []string, patterns validatePatterns) (violations Violations) {
+	violations = make(Violations, 0)
+	segment, rest := segments[0], segments[1:]
+	isArray := strings.HasSuffix(segment, "[]")
+	name := strings.TrimSuffix(segment, "[]")
+	value, valuePath := node, path
+	if name != "" {
+		value, valuePath = node.Get(escapeDocumentKey(name)), joinValidatePath(path, name)
+	}
+	if !value.Exists() {
+		if len(rest) == 0 && jItem.Required {
+			violations = append(violations, jItem.newViolation(valuePath, VALIDATE_ERROR_CODE_REQUIRED, "required"))
+		}
+		return violations
+	}
+	if !isArray {
+		if len(rest) == 0 {
+			return jItem.validateValue(value, valuePath, patterns)
+		}
+		return jItem.validatePath(value, valuePath, rest, patterns)
+	}
+	if !value.IsArray() {
+		violations = append(violations, jItem.newViolation(valuePath, VALIDATE_ERROR_CODE_TYPE, fmt.Sprintf("expected array,got:%s", value.Raw)))
+		return violations
+	}
+	for i, element := range value.Array() {
+		elementPath := joinValidatePath(valuePath, strconv.Itoa(i))
+		if len(rest) == 0 {
+			violations = append(violations, jItem.validateValue(element, elementPath, patterns)...)
+			continue
+		}
+		violations = append(violations, jItem.validatePath(element, elementPath, rest, patterns)...)
+	}
+	return violations
+}
+
+// validateValue 校验单个值,类型不符时不再做其它校验
+func (jItem JsonschemalineItem) validateValue(value gjson.Result, path string, patterns validatePatterns) (violations Violations) {
+	violations = make(Violations, 0)
+	add := func(code string, format string, args ...interface{}) {
+		violations = append(violations, jItem.newViolation(path, code, fmt.Sprintf(format, args...)))
+	}
+	if jItem.AllowEmptyValue && (value.Type == gjson.Null || (value.Type == gjson.String && value.Str == "")) {
+		return violations
+	}
+	if !matchType(jItem.Type, value) {
+		add(VALIDATE_ERROR_CODE_TYPE, "expected %s,got:%s", jItem.Type, value.Raw)
+		return violations
+	}
+	number, isNumber := value.Float(), value.Type == gjson.Number
+	if value.Type == gjson.String && jItem.Format != "" {
+		ok := true
+		number, isNumber, ok = stringFormatValue(jItem.Format, value.Str)
+		if !ok {
+			add(VALIDATE_ERROR_CODE_FORMAT, "expected format %s,got:%s", jItem.Format, value.Raw)
+			return violations
+		}
+	}
+
+	if jItem.Enum != "" && !inEnum(jItem.Enum, value) {
+		add(VALIDATE_ERROR_CODE_ENUM, "must be one of %s,got:%s", jItem.Enum, value.Raw)
+	}
+	if jItem.Const != "" && value.String() != jItem.Const {
+		add(VALIDATE_ERROR_CODE_CONST, "must be %s,got:%s", jItem.Const, value.Raw)
+	}
+
+	if isNumber { // 只校验已设置的边界,exclusiveMaximum、exclusiveMinimum 没有对应边界时不产生约束
+		if jItem.MultipleOf != nil && *jItem.MultipleOf != 0 {
+			multipleOf := *jItem.MultipleOf
+			quotient := number / multipleOf
+			if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				add(VALIDATE_ERROR_CODE_MULTIPLE_OF, "must be multiple of %v,got:%v", multipleOf, number)
+			}
+		}
+		if jItem.Maximum != nil {
+			maximum := *jItem.Maximum
+			if jItem.ExclusiveMaximum && number >= maximum {
+				add(VALIDATE_ERROR_CODE_EXCLUSIVE_MAXIMUM, "must be less than %v,got:%v", maximum, number)
+			} else if !jItem.ExclusiveMaximum && number > maximum {
+				add(VALIDATE_ERROR_CODE_MAXIMUM, "must be less than or equal to %v,got:%v", maximum, number)
+			}
+		}
+		if jItem.Minimum != nil {
+			minimum := *jItem.Minimum
+			if jItem.ExclusiveMinimum && number <= minimum {
+				add(VALIDATE_ERROR_CODE_EXCLUSIVE_MINIMUM, "must be greater than %v,got:%v", minimum, number)
+			} else if !jItem.ExclusiveMinimum && number < minimum {
+				add(VALIDATE_ERROR_CODE_MINIMUM, "must be greater than or equal to %v,got:%v", minimum, number)
+			}
+		}
+	}
+
+	switch {
+	case value.Type == gjson.String:
+		length := utf8.RuneCountInString(value.Str)
+		if jItem.MaxLength != 0 && length > jItem.MaxLength {
+			add(VALIDATE_ERROR_CODE_MAX_LENGTH, "length must be less than or equal to %d,got:%d", jItem.MaxLength, length)
+		}
+		if length < jItem.MinLength {
+			add(VALIDATE_ERROR_CODE_MIN_LENGTH, "length must be greater than or equal to %d,got:%d", jItem.MinLength, length)
+		}
+		if pattern, ok := patterns[jItem.Pattern]; ok {
+			if pattern.err != nil {
+				add(VALIDATE_ERROR_CODE_PATTERN, "invalid pattern %s: %s", jItem.Pattern, pattern.err.Error())
+			} else if !pattern.reg.MatchString(value.Str) {
+				add(VALIDATE_ERROR_CODE_PATTERN, "must match pattern %s,got:%s", jItem.Pattern, value.Raw)
+			}
+		}
+	case value.IsArray():
+		elements := value.Array()
+		if jItem.MaxItems != 0 && len(elements) > jItem.MaxItems {
+			add(VALIDATE_ERROR_CODE_MAX_ITEMS, "items must be less than or equal to %d,got:%d", jItem.MaxItems, len(elements))
+		}
+		if len(elements) < jItem.MinItems {
+			add(VALIDATE_ERROR_CODE_MIN_ITEMS, "items must be greater than or equal to %d,got:%d", jItem.MinItems, len(elements))
+		}
+		if jItem.UniqueItems {
+			exists := make(map[string]bool)
+			for _, element := range elements {
+				raw := jsonSchemaValue2String(gjson.Parse(element.Raw)) + element.Type.String()
+				if exists[raw] {
+					add(VALIDATE_ERROR_CODE_UNIQUE_ITEMS, "items must be unique,duplicate:%s", element.Raw)
+					break
+				}
+				exists[raw] = true
+			}
+		}
+	case value.IsObject():
+		count := len(value.Map())
+		if jItem.MaxProperties != 0 && count > jItem.MaxProperties {
+			add(VALIDATE_ERROR_CODE_MAX_PROPERTIES, "properties must be less than or equal to %d,got:%d", jItem.MaxProperties, count)
+		}
+		if count < jItem.MinProperties {
+			add(VALIDATE_ERROR_CODE_MIN_PROPERTIES, "properties must be greater than or equal to %d,got:%d", jItem.MinProperties, count)
+		}
+	}
+	return violations
+}
+
+func (jItem JsonschemalineItem) newViolation(path string, code string, msg string) (violation *Violation) {
+	return &Violation{Path: path, Fullname: jItem.Fullname, Code: code, Msg: msg}
+}
+
+// matchType 检查值是否符合type,未知类型不做限制
+func matchType(typ string, value gjson.Result) (ok bool) {
+	switch strings.ToLower(typ) {
+	case "string":
+		return value.Type == gjson.String
+	case "int", "integer":
+		return value.Type == gjson.Number && value.Float() == math.Trunc(value.Float())
+	case "number", "float":
+		return value.Type == gjson.Number
+	case "bool", "boolean":
+		return value.Type == gjson.True || value.Type == gjson.False
+	case "array":
+		return value.IsArray()
+	case "object":
+		return value.IsObject()
+	case "null":
+		return value.Type == gjson.Null
+	}
+	return true
+}
+
+// stringFormatValue 按已注册的format检查字符串,数值类format同时返回转换后的数值,未注册的format不做限制
+func stringFormatValue(format string, str string) (number float64, isNumber bool, ok bool) {
+	f, registered := GetFormat(format)
+	if !registered {
+		return 0, false, true
+	}
+	if f.Validate != nil && !f.Validate(str) {
+		return 0, false, false
+	}
+	if f.IsNumber {
+		number, err := strconv.ParseFloat(str, 64)
+		return number, err == nil, true
+	}
+	return 0, false, true
+}
+
+// inEnum 按字符串形式比较,兼容 enum=["1","2"] 与数值入参、enum=[1,2] 与字符串入参;enum 不是json数组时按逗号分隔
+func inEnum(enum string, value gjson.Result) (ok bool) {
+	values := make([]string, 0)
+	enumResult := gjson.Parse(enum)
+	if enumResult.IsArray() {
+		for _, e := range enumResult.Array() {
+			values = append(values, cast.ToString(e.Value()))
+		}
+	} else {
+		values = strings.Split(enum, ",")
+	}
+	return inArray(value.String(), values)
+}
+
+func joinValidatePath(path string, key string) (newPath string) {
+	key = escapeDocumentKey(key)
+	if path == "" {
+		return key
+	}
+	return fmt.Sprintf("%s.%s", path, key)
+}
@@ -0,0 +1,92 @@
+package jsonschemaline_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suifengpiao14/jsonschemaline"
+)
+
+func TestValidate(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=list
+fullname=pageSize,dst=pageSize,format=int,required,minimum=1,maximum=100
+fullname=price,dst=price,format=float,multipleOf=0.01,minimum=0,exclusiveMinimum
+fullname=status,dst=status,enum=["1","2"]
+fullname=name,dst=name,minLength=2,maxLength=4,pattern=^[a-z]+$
+fullname=tags,dst=tags,type=array,maxItems=2,uniqueItems
+fullname=items[].id,dst=items.#.id,type=int,required
+fullname=items[].kind,dst=items.#.kind,const=book,allowEmptyValue`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		data := `{"pageSize":"20","price":"9.99","status":"1","name":"abc","tags":["a","b"],"items":[{"id":1,"kind":"book"},{"id":2,"kind":""}]}`
+		violations := lineschema.Validate([]byte(data))
+		assert.Empty(t, violations)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		data := `{"pageSize":"200","price":"0","status":"3","name":"ABCDE","tags":["a","a","b"],"items":[{"id":1},{"id":2},{"id":"3","kind":"pen"},{"kind":"book"}]}`
+		violations := lineschema.Validate([]byte(data))
+		got := make(map[string]string)
+		for _, v := range violations {
+			got[v.Path] += v.Code + ";"
+		}
+		expected := map[string]string{
+			"pageSize":     jsonschemaline.VALIDATE_ERROR_CODE_MAXIMUM + ";",
+			"price":        jsonschemaline.VALIDATE_ERROR_CODE_EXCLUSIVE_MINIMUM + ";",
+			"status":       jsonschemaline.VALIDATE_ERROR_CODE_ENUM + ";",
+			"name":         jsonschemaline.VALIDATE_ERROR_CODE_MAX_LENGTH + ";" + jsonschemaline.VALIDATE_ERROR_CODE_PATTERN + ";",
+			"tags":         jsonschemaline.VALIDATE_ERROR_CODE_MAX_ITEMS + ";" + jsonschemaline.VALIDATE_ERROR_CODE_UNIQUE_ITEMS + ";",
+			"items.2.id":   jsonschemaline.VALIDATE_ERROR_CODE_TYPE + ";",
+			"items.2.kind": jsonschemaline.VALIDATE_ERROR_CODE_CONST + ";",
+			"items.3.id":   jsonschemaline.VALIDATE_ERROR_CODE_REQUIRED + ";",
+		}
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		violations := lineschema.Validate([]byte(`{"pageSize":"abc","price":"1.001"}`))
+		require.Len(t, violations, 2)
+		assert.Equal(t, "pageSize", violations[0].Path)
+		assert.Equal(t, jsonschemaline.VALIDATE_ERROR_CODE_FORMAT, violations[0].Code)
+		assert.Equal(t, "price", violations[1].Path)
+		assert.Equal(t, jsonschemaline.VALIDATE_ERROR_CODE_MULTIPLE_OF, violations[1].Code)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		violations := lineschema.Validate([]byte(`{"pageSize":`))
+		require.Len(t, violations, 1)
+		assert.Equal(t, jsonschemaline.VALIDATE_ERROR_CODE_INVALID_JSON, violations[0].Code)
+	})
+}
+
+func TestValidateZeroBound(t *testing.T) {
+	line := `version=http://json-schema.org/draft-07/schema#,direction=in,id=price
+fullname=price,dst=price,type=number,minimum=0,maximum=10,required
+fullname=discount,dst=discount,type=number,maximum=0
+fullname=score,dst=score,type=number,exclusiveMaximum,exclusiveMinimum
+fullname=code,dst=code,pattern=^[a-z]+$`
+	lineschema, err := jsonschemaline.ParseJsonschemaline(line)
+	require.NoError(t, err)
+	compiled, err := jsonschemaline.Compile(lineschema)
+	require.NoError(t, err)
+	cases := map[string]string{
+		`{"price":0,"discount":0,"score":5,"code":"abc"}`: "",
+		`{"price":-5}`:               jsonschemaline.VALIDATE_ERROR_CODE_MINIMUM,
+		`{"price":1,"discount":0.5}`: jsonschemaline.VALIDATE_ERROR_CODE_MAXIMUM,
+		`{"price":1,"score":-5}`:     "", // 没有边界时 exclusive 不产生约束
+		`{"price":1,"code":"ABC"}`:   jsonschemaline.VALIDATE_ERROR_CODE_PATTERN,
+	}
+	for data, code := range cases {
+		for _, violations := range []jsonschemaline.Violations{lineschema.Validate([]byte(data)), compiled.Validate([]byte(data))} {
+			if code == "" {
+				assert.Empty(t, violations, data)
+				continue
+			}
+			require.Len(t, violations, 1, data)
+			assert.Equal(t, code, violations[0].Code, data)
+		}
+	}
+}